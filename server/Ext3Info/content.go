@@ -13,27 +13,53 @@ import (
 	"time"
 )
 
-func GetLogicPartitions(diskName string) ([]string, []string, error) {
+func GetLogicPartitions(path string) ([]string, []string, error) {
 	partitions := make([]string, 0)
 	information := make([]string, 0)
 
 	mbr := &structures.MBR{}
-	path := stores.LoadedDiskPaths[diskName]
 	err := mbr.DeserializeMBR(path)
 	if err != nil {
 		return nil, nil, err
 	}
-	for _, part := range mbr.Mbr_partitions {
-		if part.Part_type[0] != 'E' {
-			continue
+	logicals, err := mbr.GetLogicalPartitions(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ebr := range logicals {
+		partitions = append(partitions, ebr.GetName())
+		status := "Desmontada"
+		if ebr.Ebr_status[0] == '1' {
+			status = fmt.Sprintf("Montada (%s)", strings.Trim(string(ebr.Ebr_id[:]), "\x00"))
 		}
-		return partitions, information, nil
-
+		information = append(information, fmt.Sprintf("Size: %d Fit: %c Start: %d Estado: %s", ebr.Ebr_size, rune(ebr.Ebr_fit[0]), ebr.Ebr_start, status))
 	}
 	return partitions, information, nil
 }
 
-func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]string, []string, []string, []string, error) {
+// Busca la particion (primaria o logica) por nombre y valida que este montada
+func getMountedPartitionByName(mbr *structures.MBR, diskPath, partitionName string) (*structures.PARTITION, error) {
+	DestinationPartName := strings.Trim(partitionName, "\x00")
+	for _, part := range mbr.Mbr_partitions {
+		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
+		if strings.EqualFold(partName, DestinationPartName) {
+			if part.Part_status[0] != '1' {
+				return nil, errors.New("particion no montada")
+			}
+			return &part, nil
+		}
+	}
+	ebr, err := mbr.GetLogicalPartitionByName(diskPath, DestinationPartName)
+	if err != nil {
+		return nil, errors.New("particion no encontrada")
+	}
+	if ebr.Ebr_status[0] != '1' {
+		return nil, errors.New("particion no montada")
+	}
+	return ebr.ToPartition(), nil
+}
+
+func GetAllContentByPath(diskPath, partitionName, pathToGetInfo string) ([]string, []string, []string, []string, error) {
 	mbr := &structures.MBR{}
 	var idPartition string
 	var folderList []string
@@ -41,22 +67,15 @@ func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]strin
 	var fileInfo []string
 	var folderInfo []string
 
-	diskPath := stores.LoadedDiskPaths[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return nil, nil, nil, nil, errors.New("particion no montada")
-			}
-			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
-			break
-		}
+	partition, err := getMountedPartitionByName(mbr, diskPath, partitionName)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
+	idPartition = strings.TrimRight(string(partition.Part_id[:]), "\x00")
 	superBlock, _, _, err := stores.GetMountedPartitionSuperblock(idPartition)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -69,55 +88,26 @@ func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]strin
 		return nil, nil, nil, nil, errors.New("no se puede aplicar este reporte sobre un archivo")
 	}
 
-	for i, blockIndex := range inodoBase.I_block {
-		if blockIndex == -1 {
-			continue
+	blocks, err := superBlock.GetInodeBlocks(diskPath, inodoBase)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for _, blockIndex := range blocks {
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+		if err != nil {
+			return nil, nil, nil, nil, err
 		}
-		if i >= 14 {
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(superBlock.S_block_start+(superBlock.S_block_size*blockIndex)))
-			if err != nil {
-				return nil, nil, nil, nil, err
+		for i := 2; i < len(block.B_content); i++ {
+			content := block.B_content[i]
+			if content.B_inodo == -1 {
+				continue
 			}
-			for _, value := range pointerBlock.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(value*superBlock.S_block_size)))
-				if err != nil {
-					return nil, nil, nil, nil, err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					if content.B_inodo == -1 {
-						continue
-					}
-					fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
-					if err != nil {
-						return nil, nil, nil, nil, err
-					}
-				}
-			}
-
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+			fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
 			if err != nil {
 				return nil, nil, nil, nil, err
 			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				if content.B_inodo == -1 {
-					continue
-				}
-				fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
-				if err != nil {
-					return nil, nil, nil, nil, err
-				}
-			}
 		}
-
 	}
 	return fileList, folderList, fileInfo, folderInfo, nil
 }
@@ -154,27 +144,20 @@ func getInformationByInode(fileList, folderList, fileInfo, folderInfo []string,
 	return fileList, folderList, fileInfo, folderInfo, nil
 }
 
-func GetContentFromFile(diskName, partitionName, pathToGetInfo string) (string, error) {
+func GetContentFromFile(diskPath, partitionName, pathToGetInfo string) (string, error) {
 	mbr := &structures.MBR{}
 	var result string
 	var idPartition string
-	diskPath := stores.LoadedDiskPaths[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return "", err
 	}
 	// Se obtiene el id
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return "", errors.New("particion no montada")
-			}
-			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
-			break
-		}
+	partition, err := getMountedPartitionByName(mbr, diskPath, partitionName)
+	if err != nil {
+		return "", err
 	}
+	idPartition = strings.TrimRight(string(partition.Part_id[:]), "\x00")
 	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(idPartition)
 	if err != nil {
 		return "", err
@@ -226,25 +209,18 @@ func getGroupById(id int32, idPartition string) (string, error) {
 	return "", errors.New("no se encontro el usuario")
 }
 
-func GetJournal(diskName, partitionName string) ([]string, []string, []string, []string, error) {
+func GetJournal(diskPath, partitionName string) ([]string, []string, []string, []string, error) {
 	mbr := &structures.MBR{}
 	var partitionStart int32
-	diskPath := stores.LoadedDiskPaths[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return nil, nil, nil, nil, errors.New("particion no montada")
-			}
-			partitionStart = part.Part_start
-			break
-		}
+	partition, err := getMountedPartitionByName(mbr, diskPath, partitionName)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
+	partitionStart = partition.Part_start
 	journal := &structures.Journal{}
 	err = journal.Deserialize(diskPath, int64(partitionStart+int32(binary.Size(structures.SuperBlock{}))))
 	if err != nil {
@@ -298,26 +274,19 @@ func getInformationJournals(journal *structures.Journal, diskPath string, comman
 	return getInformationJournals(neoJournal, diskPath, commandList, pathList, contentList, dateList)
 }
 
-func IsExt3(diskName, partitionName string) (bool, error) {
+func IsExt3(diskPath, partitionName string) (bool, error) {
 	mbr := &structures.MBR{}
 	var idPartition string
-	diskPath := stores.LoadedDiskPaths[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return false, err
 	}
 	// Se obtiene el id
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return false, errors.New("particion no montada")
-			}
-			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
-			break
-		}
+	partition, err := getMountedPartitionByName(mbr, diskPath, partitionName)
+	if err != nil {
+		return false, err
 	}
+	idPartition = strings.TrimRight(string(partition.Part_id[:]), "\x00")
 	partitionSuperblock, _, _, err := stores.GetMountedPartitionSuperblock(idPartition)
 	if err != nil {
 		return false, err
@@ -1,84 +1,172 @@
 package analyzer
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"server/commands"
+	"server/lexer"
+	"server/result"
 	"strings"
 )
 
 type EXECUTE struct {
-	path string
+	path   string
+	strict bool
 }
 
-func ParseExecute(tokens []string) (string, error) {
-	cmd := &EXECUTE{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+// Script en ejecucion. Los execute de sus lineas se resuelven respecto a dir y
+// comparten vars, como si el otro script estuviera escrito en ese lugar.
+type scriptContext struct {
+	path   string
+	dir    string
+	vars   map[string]string
+	strict bool
+}
 
-	if len(matches) != len(tokens) {
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
+// Scripts que se estan ejecutando, el ultimo es el actual
+var running []*scriptContext
+
+var variableRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var executeParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta del script, relativa al script que lo incluye"},
+	{Name: "-strict", Type: lexer.Flag, Help: "se detiene en el primer comando que falle"},
+}
+
+func init() {
+	commands.Register(&commands.Command{
+		Name:        "execute",
+		Description: "Ejecuta un script",
+		Params:      executeParams,
+		Handler:     commands.Handle(ParseExecute),
+	})
+}
+
+func ParseExecute(args lexer.Args) (*result.Script, error) {
+	cmd := &EXECUTE{path: args["-path"], strict: args.Has("-strict")}
+
+	return commandExecute(cmd)
+}
+
+func commandExecute(exec *EXECUTE) (*result.Script, error) {
+	context := &scriptContext{path: exec.path, vars: make(map[string]string), strict: exec.strict}
+	if len(running) > 0 {
+		parent := running[len(running)-1]
+		if !filepath.IsAbs(context.path) {
+			context.path = filepath.Join(parent.dir, context.path)
 		}
+		context.vars = parent.vars
+		context.strict = context.strict || parent.strict
 	}
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
-
-		switch key {
-		case "-path":
-			if len(kv) != 2 {
-				return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-			}
-			value := kv[1]
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.path = value
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
+
+	path, err := filepath.Abs(context.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range running {
+		if script.path == path {
+			return nil, fmt.Errorf("inclusion circular: %s ya se esta ejecutando", exec.path)
 		}
 	}
+	context.path = path
+	context.dir = filepath.Dir(path)
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
+	lines, err := getCommands(path)
+	if err != nil {
+		return nil, err
 	}
+	return runScript(exec.path, lines, context), nil
+}
 
-	outcomeCmd, err := commandExecute(cmd)
-	if err != nil {
-		return "", err
+// Ejecuta las lineas de un script en orden. Sin strict sigue despues de los
+// errores; con strict se detiene en el primero. Si name es un archivo sus
+// execute se resuelven respecto a su carpeta, si no (scripts del API) respecto
+// a la carpeta actual.
+func RunScript(name string, lines []string, strict bool) *result.Script {
+	context := &scriptContext{path: name, dir: ".", vars: make(map[string]string), strict: strict}
+	if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() {
+		path, err := filepath.Abs(name)
+		if err == nil {
+			context.path = path
+			context.dir = filepath.Dir(path)
+		}
 	}
+	return runScript(name, lines, context)
+}
+
+func runScript(name string, lines []string, context *scriptContext) *result.Script {
+	running = append(running, context)
+	defer func() {
+		running = running[:len(running)-1]
+	}()
 
-	return fmt.Sprintf("EXECUTE: ejecutado correctamente.\n%s", outcomeCmd), nil
+	script := &result.Script{Path: name, Strict: context.strict}
+	for i, line := range lines {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "exit" {
+			break
+		} else if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
 
+		outcome := runLine(line, context)
+		script.Total++
+		if failed(outcome) {
+			script.Failed++
+			if !outcome.OK() {
+				outcome.Message = fmt.Sprintf("%s (linea %d): %s", name, i+1, outcome.Message)
+			}
+		}
+		script.Results = append(script.Results, outcome)
+		if failed(outcome) && context.strict {
+			script.StoppedAt = i + 1
+			break
+		}
+	}
+	return script
 }
 
-func commandExecute(exec *EXECUTE) (string, error) {
-	commands, err := getCommands(exec.path)
+func runLine(line string, context *scriptContext) result.Result {
+	expanded, err := expandVariables(line, context.vars)
 	if err != nil {
-		return "", err
+		return result.New(strings.ToLower(strings.Fields(line)[0]), nil, err)
 	}
-	var outcome string
-	for _, cmd := range commands {
-		if cmd == "exit" {
-			break
-		} else if strings.HasPrefix(cmd, "#") {
-			continue
+	return Analyzer(expanded)
+}
+
+// Reemplaza ${NOMBRE} con las variables del script (set) o, si no hay, con
+// las variables de entorno
+func expandVariables(line string, vars map[string]string) (string, error) {
+	var missing string
+	expanded := variableRe.ReplaceAllStringFunc(line, func(match string) string {
+		name := variableRe.FindStringSubmatch(match)[1]
+		if value, exists := vars[name]; exists {
+			return value
 		}
-		msg, err := Analyzer(cmd)
-		if err != nil {
-			outcome += fmt.Sprintf("Error: %v\n", err)
-			continue
-		} else {
-			outcome += fmt.Sprintf("%v\n", msg)
+		if value, exists := os.LookupEnv(name); exists {
+			return value
 		}
+		if missing == "" {
+			missing = name
+		}
+		return match
+	})
+	if missing != "" {
+		return "", fmt.Errorf("variable no definida: %s", missing)
 	}
-	return outcome, nil
+	return expanded, nil
+}
 
+// Un execute cuenta como fallido si fallo alguno de sus comandos
+func failed(outcome result.Result) bool {
+	for _, line := range outcome.Lines() {
+		if !line.OK() {
+			return true
+		}
+	}
+	return false
 }
 
 func getCommands(path string) ([]string, error) {
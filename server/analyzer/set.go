@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"server/commands"
+	"server/lexer"
+)
+
+type SET struct {
+	name  string
+	value string
+}
+
+var setParams = []lexer.Param{
+	{Name: "-name", Required: true, Pattern: `[A-Za-z_][A-Za-z0-9_]*`, Help: "nombre de la variable, se usa como ${NOMBRE}"},
+	{Name: "-value", Required: true, AllowEmpty: true, Help: "valor de la variable"},
+}
+
+func init() {
+	commands.Register(&commands.Command{
+		Name:        "set",
+		Description: "Define una variable del script",
+		Params:      setParams,
+		Handler:     commands.Handle(ParseSet),
+	})
+}
+
+func ParseSet(args lexer.Args) (string, error) {
+	cmd := &SET{name: args["-name"], value: args["-value"]}
+
+	// Las variables viven mientras corre el script (y los que incluye)
+	if len(running) == 0 {
+		return "", errors.New("set solo se puede usar dentro de un script")
+	}
+	running[len(running)-1].vars[cmd.name] = cmd.value
+	return fmt.Sprintf("SET: %s=%s", cmd.name, cmd.value), nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"maps"
+	"server/result"
+	"server/stores"
+	"server/structures"
+	"slices"
+	"strings"
+)
+
+type disk struct {
+	Name       string                 `json:"name"`
+	Path       string                 `json:"path"`
+	Size       int32                  `json:"size"`
+	Fit        string                 `json:"fit"`
+	Partitions []result.PartitionInfo `json:"partitions"`
+}
+
+func getDisks() ([]disk, error) {
+	disks := make([]disk, 0, len(stores.LoadedDiskPaths))
+	for _, path := range slices.Sorted(maps.Keys(stores.LoadedDiskPaths)) {
+		mbr := &structures.MBR{}
+		err := mbr.DeserializeMBR(path)
+		if err != nil {
+			return nil, err
+		}
+		partitions, err := getPartitions(path)
+		if err != nil {
+			return nil, err
+		}
+		disks = append(disks, disk{
+			Name:       stores.LoadedDiskPaths[path],
+			Path:       path,
+			Size:       mbr.Mbr_size,
+			Fit:        string(mbr.Mbr_disk_fit[:]),
+			Partitions: partitions,
+		})
+	}
+	return disks, nil
+}
+
+// Primarias, extendida y logicas del disco en el orden en que estan
+func getPartitions(path string) ([]result.PartitionInfo, error) {
+	mbr := &structures.MBR{}
+	err := mbr.DeserializeMBR(path)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]result.PartitionInfo, 0)
+	for _, partition := range mbr.Mbr_partitions {
+		if partition.Part_start == -1 || partition.Part_size <= 0 {
+			continue
+		}
+		partitions = append(partitions, partitionInfo(path, &partition))
+		if partition.Part_type[0] != 'E' {
+			continue
+		}
+		logicals, err := mbr.GetLogicalPartitions(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, ebr := range logicals {
+			partitions = append(partitions, partitionInfo(path, ebr.ToPartition()))
+		}
+	}
+	slices.SortFunc(partitions, func(a, b result.PartitionInfo) int {
+		return int(a.Start - b.Start)
+	})
+	return partitions, nil
+}
+
+func partitionInfo(path string, partition *structures.PARTITION) result.PartitionInfo {
+	info := result.PartitionInfo{
+		Disk:    path,
+		Name:    strings.Trim(string(partition.Part_name[:]), "\x00 "),
+		Type:    string(partition.Part_type[:]),
+		Fit:     string(partition.Part_fit[:]),
+		Start:   partition.Part_start,
+		Size:    partition.Part_size,
+		Mounted: partition.Part_status[0] == '1',
+	}
+	if info.Mounted {
+		info.ID = strings.TrimRight(string(partition.Part_id[:]), "\x00")
+	}
+	return info
+}
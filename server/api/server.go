@@ -0,0 +1,224 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	ext3 "server/Ext3Info"
+	"server/analyzer"
+	"server/result"
+	"server/stores"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Los comandos usan el estado global (sesion, particiones montadas), asi que
+// las peticiones se atienden de una en una
+var mutex sync.Mutex
+
+type commandRequest struct {
+	Command string `json:"command"`
+}
+
+type scriptRequest struct {
+	Name   string `json:"name"`
+	Script string `json:"script"`
+	Strict bool   `json:"strict"`
+}
+
+type entry struct {
+	Name string `json:"name"`
+	Type string `json:"type"` //file o folder
+	Info string `json:"info"`
+}
+
+type journalEntry struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Date      string `json:"date"`
+}
+
+/*
+Rutas:
+
+	POST /api/command                          {"command": "mkdisk -size=5"}
+	POST /api/script                           {"name": "a.smia", "script": "...", "strict": true}
+	GET  /api/disks
+	GET  /api/disks/:disk/partitions
+	GET  /api/disks/:disk/partitions/:partition/ls?path=/
+	GET  /api/disks/:disk/partitions/:partition/file?path=/users.txt
+	GET  /api/disks/:disk/partitions/:partition/journal
+
+Los comandos responden con los resultados en el mismo formato que la salida
+json de la consola, una entrada por comando. Si el servidor tiene token, cada
+peticion lo debe enviar en el encabezado Authorization: Bearer <token>. :disk es el nombre del disco o, si
+hay varios con el mismo nombre, su ruta absoluta escapada (%2Ftmp%2FA.dsk).
+*/
+func NewServer(token string) *fiber.App {
+	app := fiber.New(fiber.Config{
+		AppName:               "MIA",
+		DisableStartupMessage: true,
+		ErrorHandler:          errorHandler,
+	})
+	if token != "" {
+		app.Use(func(c *fiber.Ctx) error {
+			received := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(received), []byte(token)) != 1 {
+				return fiber.NewError(fiber.StatusUnauthorized, "token invalido o ausente")
+			}
+			return c.Next()
+		})
+	}
+	app.Use(func(c *fiber.Ctx) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return c.Next()
+	})
+
+	routes := app.Group("/api")
+	routes.Post("/command", runCommand)
+	routes.Post("/script", runScript)
+	routes.Get("/disks", listDisks)
+	routes.Get("/disks/:disk/partitions", listPartitions)
+	routes.Get("/disks/:disk/partitions/:partition/ls", listDirectory)
+	routes.Get("/disks/:disk/partitions/:partition/file", readFile)
+	routes.Get("/disks/:disk/partitions/:partition/journal", readJournal)
+	return app
+}
+
+func runCommand(c *fiber.Ctx) error {
+	request := &commandRequest{}
+	err := c.BodyParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "el cuerpo debe ser un JSON con el campo command")
+	}
+	if strings.TrimSpace(request.Command) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "el comando no puede estar vacio")
+	}
+	return c.JSON(analyzer.Analyzer(request.Command).Lines())
+}
+
+func runScript(c *fiber.Ctx) error {
+	request := &scriptRequest{}
+	err := c.BodyParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "el cuerpo debe ser un JSON con el campo script")
+	}
+	if request.Name == "" {
+		request.Name = "script"
+	}
+	script := analyzer.RunScript(request.Name, strings.Split(request.Script, "\n"), request.Strict)
+	return c.JSON(result.New("execute", script, nil).Lines())
+}
+
+func listDisks(c *fiber.Ctx) error {
+	disks, err := getDisks()
+	if err != nil {
+		return err
+	}
+	return c.JSON(disks)
+}
+
+func listPartitions(c *fiber.Ctx) error {
+	path, err := diskPath(c.Params("disk"))
+	if err != nil {
+		return err
+	}
+	partitions, err := getPartitions(path)
+	if err != nil {
+		return err
+	}
+	return c.JSON(partitions)
+}
+
+func listDirectory(c *fiber.Ctx) error {
+	disk, err := diskPath(c.Params("disk"))
+	if err != nil {
+		return err
+	}
+	files, folders, fileInfo, folderInfo, err := ext3.GetAllContentByPath(disk, c.Params("partition"), c.Query("path", "/"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	entries := make([]entry, 0, len(files)+len(folders))
+	for i, name := range folders {
+		entries = append(entries, entry{Name: name, Type: "folder", Info: folderInfo[i]})
+	}
+	for i, name := range files {
+		entries = append(entries, entry{Name: name, Type: "file", Info: fileInfo[i]})
+	}
+	return c.JSON(entries)
+}
+
+func readFile(c *fiber.Ctx) error {
+	disk, err := diskPath(c.Params("disk"))
+	if err != nil {
+		return err
+	}
+	path := c.Query("path")
+	if path == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "falta el parametro path")
+	}
+	content, err := ext3.GetContentFromFile(disk, c.Params("partition"), path)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(result.CatFile{Path: path, Content: content})
+}
+
+func readJournal(c *fiber.Ctx) error {
+	disk, err := diskPath(c.Params("disk"))
+	if err != nil {
+		return err
+	}
+	isExt3, err := ext3.IsExt3(disk, c.Params("partition"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if !isExt3 {
+		return fiber.NewError(fiber.StatusBadRequest, "la particion no es EXT3, no tiene journaling")
+	}
+	operations, paths, contents, dates, err := ext3.GetJournal(disk, c.Params("partition"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	entries := make([]journalEntry, 0, len(operations))
+	for i := range operations {
+		entries = append(entries, journalEntry{
+			Operation: strings.TrimRight(operations[i], "\x00"),
+			Path:      strings.TrimRight(paths[i], "\x00"),
+			Content:   strings.TrimRight(contents[i], "\x00"),
+			Date:      dates[i],
+		})
+	}
+	return c.JSON(entries)
+}
+
+func diskPath(name string) (string, error) {
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	path, err := stores.FindDisk(name)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return path, nil
+}
+
+// Los errores salen con el mismo formato que un comando fallido
+func errorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code = fiberErr.Code
+	}
+	return c.Status(code).JSON(result.Result{
+		Status:  result.StatusError,
+		Command: c.Method() + " " + c.Path(),
+		Message: err.Error(),
+	})
+}
@@ -1,86 +1,61 @@
 package commands
 
 import (
-	"errors"
-	"fmt"
-	"regexp"
+	"maps"
+	"server/lexer"
+	"server/result"
 	stores "server/stores"
 	utils "server/utils"
-	"strconv"
-	"strings"
+	"slices"
 )
 //hola
 type CAT struct {
 	files map[int]string
 }
 
-func ParseCat(tokens []string) (string, error) {
-	cmd := &CAT{}
-	cmd.files = make(map[int]string)
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-file\d+="[^"]+"|-file\d+=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-		commandKey := regexp.MustCompile("[a-zA-Z-]+")
-		numberKey := regexp.MustCompile("[0-9]+")
-
-		letters := commandKey.FindString(key)
-		number := numberKey.FindString(key)
+var catParams = []lexer.Param{
+	{Name: "-file", Numbered: true, Required: true, Help: "ruta del archivo, se pueden pasar varios (-file1, -file2, ...)"},
+}
 
-		switch letters {
-		case "-file":
-			numberFile, err := strconv.Atoi(number)
-			if err != nil || numberFile <= 0 {
-				return "", errors.New("el numero de fileN debe ser mayor a 0")
-			}
-			if value == "" {
-				return "", errors.New("el fileN no puede estar vacio")
-			}
-			cmd.files[numberFile] = value
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "cat",
+		Description: "Muestra el contenido de uno o varios archivos",
+		Params:      catParams,
+		Handler:     Handle(ParseCat),
+	})
+}
 
-	if len(cmd.files) == 0 {
-		return "", errors.New("falta al menos un parametro requerido: -fileN")
-	}
+func ParseCat(args lexer.Args) (*result.CatContents, error) {
+	cmd := &CAT{files: args.Numbered("-file")}
 
 	// Logica de Cat
 	content, err := commandCat(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	fmt.Println(content)
-
 	return content, nil
 
 }
 
-func commandCat(cat *CAT) (string, error) {
+func commandCat(cat *CAT) (*result.CatContents, error) {
 	// Tomar en cuenta que el idPartition correspondara al id actual en el q este el usuario
-	var result string
+	contents := &result.CatContents{Files: make([]result.CatFile, 0, len(cat.files))}
 	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	for _, pathToGetInfo := range cat.files {
+	// Los archivos se muestran en el orden de su numero (file1, file2, ...)
+	numbers := slices.Sorted(maps.Keys(cat.files))
+	for _, number := range numbers {
+		pathToGetInfo := cat.files[number]
 		parentDirs, destDir := utils.GetParentDirectories(pathToGetInfo)
 
 		content, err := partitionSuperblock.ContentFromFileCat(partitionPath, 0, parentDirs, destDir)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		result += content + "\n"
+		contents.Files = append(contents.Files, result.CatFile{Path: pathToGetInfo, Content: content})
 	}
-	return result, nil
+	return contents, nil
 }
@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	stores "server/stores"
+	"server/structures"
+	"slices"
+	"strings"
+	"time"
+)
+
+type CHGRP struct {
+	user   string
+	group  string
+	add    bool
+	remove bool
+}
+
+var chgrpParams = []lexer.Param{
+	{Name: "-user", Required: true, Help: "nombre del usuario"},
+	{Name: "-grp", Required: true, Help: "grupo"},
+	{Name: "-add", Type: lexer.Flag, Help: "agrega el grupo como grupo extra"},
+	{Name: "-remove", Type: lexer.Flag, Help: "quita el grupo de los grupos extra"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "chgrp",
+		Description: "Cambia el grupo principal de un usuario o sus grupos extra",
+		Params:      chgrpParams,
+		Handler:     Handle(ParseChgrp),
+	})
+}
+
+func ParseChgrp(args lexer.Args) (string, error) {
+	cmd := &CHGRP{
+		user:   args["-user"],
+		group:  args["-grp"],
+		add:    args.Has("-add"),
+		remove: args.Has("-remove"),
+	}
+
+	if cmd.add && cmd.remove {
+		return "", errors.New("no se puede usar -add y -remove al mismo tiempo")
+	}
+
+	err := CommandChgrp(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case cmd.add:
+		return fmt.Sprintf("CHGRP: usuario %s agregado al grupo %s exitosamente", cmd.user, cmd.group), nil
+	case cmd.remove:
+		return fmt.Sprintf("CHGRP: usuario %s quitado del grupo %s exitosamente", cmd.user, cmd.group), nil
+	}
+	return fmt.Sprintf("CHGRP: grupo de %s cambiado a %s exitosamente", cmd.user, cmd.group), nil
+}
+
+/*
+Cambia el grupo principal del usuario, o con -add y -remove sus grupos
+adicionales. Los adicionales van en una sexta columna de users.txt separados
+por ';' (id,U,grupo,usuario,pass,grupo2;grupo3). Los cambios aplican en el
+siguiente login del usuario.
+*/
+func CommandChgrp(chgrp *CHGRP) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	if stores.LogedUser != "root" {
+		return errors.New("este comando solo lo puede ejecutar el usuario root")
+	}
+	contentUsersTxt, err := getContetnUsersTxt(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+
+	mode := chgrpMode(chgrp.add, chgrp.remove)
+	err = applyChgrp(chgrp.user, chgrp.group, mode, contentMatrix)
+	if err != nil {
+		return err
+	}
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if partitionSuperblock.IsExt3() {
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = OverrideUserstxt(partitionSuperblock, partitionPath, reformUserstxt(contentMatrix))
+	if err != nil {
+		return err
+	}
+
+	if partitionSuperblock.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'g', 'r', 'p'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		fullContent := fmt.Sprintf("%s/%s/%s", chgrp.user, chgrp.group, mode)
+		copy(journalDirectory.J_content.I_content[:], fullContent)
+		err = partitionSuperblock.AddJournal(journalDirectory, partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = partitionSuperblock.Serialize(partitionPath, int64(mountedPartition.Part_start))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func chgrpMode(add, remove bool) string {
+	if add {
+		return "add"
+	}
+	if remove {
+		return "remove"
+	}
+	return "set"
+}
+
+func applyChgrp(userName, groupName, mode string, matrix [][]string) error {
+	if !groupExists(groupName, matrix) {
+		return errors.New("el grupo especificado no existe")
+	}
+	for i, row := range matrix {
+		if len(row) < 5 || row[1] != "U" || row[0] == "0" || row[3] != userName {
+			continue
+		}
+		extras := getExtraGroups(row)
+		position := slices.Index(extras, groupName)
+		switch mode {
+		case "set":
+			row[2] = groupName
+			if position != -1 {
+				extras = slices.Delete(extras, position, position+1)
+			}
+		case "add":
+			if row[2] == groupName || position != -1 {
+				return errors.New("el usuario ya pertenece al grupo")
+			}
+			extras = append(extras, groupName)
+		case "remove":
+			if position == -1 {
+				return errors.New("el grupo no es un grupo adicional del usuario")
+			}
+			extras = slices.Delete(extras, position, position+1)
+		default:
+			return errors.New("entrada de chgrp invalida")
+		}
+		matrix[i] = setExtraGroups(row, extras)
+		return nil
+	}
+	return errors.New("el nombre de usuario no existe")
+}
+
+func groupExists(groupName string, matrix [][]string) bool {
+	for _, row := range matrix {
+		if row[1] == "G" && row[0] != "0" && row[2] == groupName {
+			return true
+		}
+	}
+	return false
+}
+
+func getExtraGroups(row []string) []string {
+	if len(row) < 6 || row[5] == "" {
+		return nil
+	}
+	return strings.Split(row[5], ";")
+}
+
+func setExtraGroups(row []string, groups []string) []string {
+	row = row[:5]
+	if len(groups) == 0 {
+		return row
+	}
+	return append(row, strings.Join(groups, ";"))
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"time"
+)
+
+type CHMOD struct {
+	path string
+	ugo  string
+	r    bool
+}
+
+var chmodParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta"},
+	{Name: "-ugo", Required: true, Pattern: `[0-7]{3}`, Help: "permisos de usuario, grupo y otros"},
+	{Name: "-r", Type: lexer.Flag, Help: "aplica tambien al contenido de la carpeta"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "chmod",
+		Description: "Cambia los permisos de un archivo o carpeta",
+		Params:      chmodParams,
+		Handler:     Handle(ParseChmod),
+	})
+}
+
+func ParseChmod(args lexer.Args) (string, error) {
+	cmd := &CHMOD{path: args["-path"], ugo: args["-ugo"], r: args.Has("-r")}
+
+	err := CommandChmod(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CHMOD: permisos de %s cambiados a %s exitosamente", cmd.path, cmd.ugo), nil
+}
+
+func CommandChmod(chmod *CHMOD) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(chmod.path, "")
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyChmod(sb, diskPath, chmod.path, chmod.ugo, chmod.r)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'm', 'o', 'd'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		fullContent := chmod.ugo
+		if chmod.r {
+			fullContent += "/r"
+		}
+		copy(journalDirectory.J_content.I_path[:], chmod.path)
+		copy(journalDirectory.J_content.I_content[:], fullContent)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Con -r se delega en ChmodRecursive, que solo cambia los inodos de los que el
+// usuario es propietario (o todos si es root)
+func applyChmod(sb *structures.SuperBlock, diskPath, path, ugo string, recursive bool) error {
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	outcome, err := inode.HasPermissionsChmod(utils.LogedUserID, utils.LogedUserGroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("solo el propietario o root pueden cambiar los permisos")
+	}
+	if recursive {
+		return sb.ChmodRecursive(diskPath, inodeIndex, ugo, utils.LogedUserID, utils.LogedUserGroupID)
+	}
+	copy(inode.I_perm[:], []byte(ugo))
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+inodeIndex*sb.S_inode_size))
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"time"
+)
+
+type CHOWN struct {
+	path string
+	user string
+	r    bool
+}
+
+var chownParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta"},
+	{Name: "-usr", Required: true, Help: "usuario nuevo"},
+	{Name: "-r", Type: lexer.Flag, Help: "aplica tambien al contenido de la carpeta"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "chown",
+		Description: "Cambia el propietario de un archivo o carpeta",
+		Params:      chownParams,
+		Handler:     Handle(ParseChown),
+	})
+}
+
+func ParseChown(args lexer.Args) (string, error) {
+	cmd := &CHOWN{path: args["-path"], user: args["-usr"], r: args.Has("-r")}
+
+	err := CommandChown(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CHOWN: %s ahora pertenece a %s", cmd.path, cmd.user), nil
+}
+
+func CommandChown(chown *CHOWN) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(chown.path, chown.user)
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyChown(sb, diskPath, chown.path, chown.user, chown.r)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'o', 'w', 'n'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		fullContent := chown.user
+		if chown.r {
+			fullContent += "/r"
+		}
+		copy(journalDirectory.J_content.I_path[:], chown.path)
+		copy(journalDirectory.J_content.I_content[:], fullContent)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyChown(sb *structures.SuperBlock, diskPath, path, user string, recursive bool) error {
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	neoOwnerID, err := getUserIDByName(user, getContentMatrixUsers(contentUsersTxt))
+	if err != nil {
+		return err
+	}
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	outcome, err := inode.HasPermissionsChmod(utils.LogedUserID, utils.LogedUserGroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("solo el propietario o root pueden cambiar el propietario")
+	}
+	if recursive {
+		return sb.ChownRecursive(diskPath, inodeIndex, utils.LogedUserID, utils.LogedUserGroupID, neoOwnerID, true)
+	}
+	inode.I_uid = neoOwnerID
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+inodeIndex*sb.S_inode_size))
+}
+
+func getUserIDByName(userName string, matrix [][]string) (int32, error) {
+	for _, row := range matrix {
+		if row[1] != "U" || row[0] == "0" {
+			continue
+		}
+		if row[3] == userName {
+			num, err := strconv.Atoi(row[0])
+			if err != nil {
+				return -1, err
+			}
+			return int32(num), nil
+		}
+	}
+	return -1, errors.New("el usuario no existe")
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strings"
+	"time"
+)
+
+type COPY struct {
+	path    string
+	destino string
+}
+
+var copyParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta a copiar"},
+	{Name: "-destino", Required: true, Help: "carpeta destino"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "copy",
+		Description: "Copia un archivo o carpeta",
+		Params:      copyParams,
+		Handler:     Handle(ParseCopy),
+	})
+}
+
+func ParseCopy(args lexer.Args) (string, error) {
+	cmd := &COPY{path: args["-path"], destino: args["-destino"]}
+
+	err := CommandCopy(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("COPY: %s copiado a %s exitosamente", cmd.path, cmd.destino), nil
+}
+
+func CommandCopy(copyCmd *COPY) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(copyCmd.path, copyCmd.destino)
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyCopy(sb, diskPath, copyCmd.path, copyCmd.destino)
+	if err != nil {
+		return err
+	}
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'o', 'p', 'y'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], copyCmd.path)
+		copy(journalDirectory.J_content.I_content[:], copyCmd.destino)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copia el archivo o la carpeta dentro del destino. De las carpetas solo se
+// copian los elementos que el usuario puede leer.
+func applyCopy(sb *structures.SuperBlock, diskPath, path, destino string) error {
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	if inodeIndex == 0 {
+		return errors.New("no se puede copiar la carpeta raiz")
+	}
+	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("no tiene permisos de lectura sobre el origen")
+	}
+	destIndex, name, err := resolveDestination(sb, diskPath, path, destino)
+	if err != nil {
+		return err
+	}
+
+	var newIndex int32
+	if inode.I_type[0] == '0' {
+		inside, err := sb.IsInSubtree(diskPath, inodeIndex, destIndex)
+		if err != nil {
+			return err
+		}
+		if inside {
+			return errors.New("no se puede copiar una carpeta dentro de si misma")
+		}
+		newIndex, err = sb.CopyInode0(diskPath, inodeIndex, destIndex)
+		if err != nil {
+			return err
+		}
+	} else {
+		newIndex, err = sb.CopyInode1(diskPath, inodeIndex)
+		if err != nil {
+			return err
+		}
+	}
+	return sb.AddEntryToFolder(diskPath, destIndex, name, newIndex)
+}
+
+// Ruta de la carpeta que contiene a path
+func getParentPath(path string) string {
+	position := strings.LastIndex(strings.TrimRight(path, "/"), "/")
+	if position <= 0 {
+		return "/"
+	}
+	return path[:position]
+}
+
+// Valida que el destino sea una carpeta con permiso de escritura y que no tenga
+// ya una entrada con el mismo nombre que el origen
+func resolveDestination(sb *structures.SuperBlock, diskPath, path, destino string) (int32, string, error) {
+	_, name := utils.GetParentDirectories(path)
+	destInode, destIndex, err := reports.UbicarInodo(sb, destino, diskPath)
+	if err != nil {
+		return -1, "", err
+	}
+	if destInode.I_type[0] != '0' {
+		return -1, "", errors.New("el destino debe ser una carpeta")
+	}
+	outcome, err := destInode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return -1, "", err
+	}
+	if !outcome {
+		return -1, "", errors.New("no tiene permisos de escritura en el destino")
+	}
+	existing, err := sb.FindEntryInFolder(diskPath, destIndex, name)
+	if err != nil {
+		return -1, "", err
+	}
+	if existing != -1 {
+		return -1, "", fmt.Errorf("ya existe %s en el destino", name)
+	}
+	return destIndex, name, nil
+}
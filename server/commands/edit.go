@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"time"
+)
+
+type EDIT struct {
+	path      string
+	contenido string
+}
+
+var editParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta del archivo"},
+	{Name: "-contenido", Required: true, Help: "archivo de la computadora con el contenido nuevo"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "edit",
+		Description: "Reemplaza el contenido de un archivo",
+		Params:      editParams,
+		Handler:     Handle(ParseEdit),
+	})
+}
+
+func ParseEdit(args lexer.Args) (string, error) {
+	cmd := &EDIT{path: args["-path"], contenido: args["-contenido"]}
+
+	err := CommandEdit(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("EDIT: %s editado exitosamente", cmd.path), nil
+}
+
+func CommandEdit(edit *EDIT) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	fileContent, err := os.ReadFile(edit.contenido)
+	if err != nil {
+		return err
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(edit.path, "")
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), len(utils.SplitStringIntoChunks(string(fileContent)))+1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyEdit(sb, diskPath, edit.path, string(fileContent))
+	if err != nil {
+		// Los bitmaps pudieron cambiar antes del error, el superbloque se
+		// guarda igual para que sus contadores no queden desfasados
+		serializeErr := sb.Serialize(diskPath, int64(partition.Part_start))
+		if serializeErr != nil {
+			return serializeErr
+		}
+		return err
+	}
+
+	if sb.IsExt3() {
+		// La primera entrada guarda el tamano y las siguientes el contenido en
+		// partes de 64 bytes, asi dos edits seguidos no se mezclan al recuperar
+		contentList := utils.SplitStringIntoChunks(string(fileContent))
+		contentList = append([]string{strconv.Itoa(len(fileContent))}, contentList...)
+		for _, content := range contentList {
+			journalDirectory := &structures.Journal{
+				J_next: -1,
+				J_content: structures.Information{
+					I_operation: [10]byte{'e', 'd', 'i', 't'},
+					I_path:      [74]byte{},
+					I_content:   [64]byte{},
+					I_date:      float32(time.Now().Unix()),
+				},
+			}
+			copy(journalDirectory.J_content.I_path[:], edit.path)
+			copy(journalDirectory.J_content.I_content[:], content)
+			err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	serializeErr := sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+	return serializeErr
+}
+
+func applyEdit(sb *structures.SuperBlock, diskPath, path, content string) error {
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '1' {
+		return errors.New("el path no corresponde a un archivo")
+	}
+	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("no tiene permisos de escritura sobre el archivo")
+	}
+	return sb.RewriteFile(diskPath, inodeIndex, content)
+}
@@ -1,137 +1,101 @@
 package commands
 
 import (
-	"bufio"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
+	"server/config"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	"server/utils"
-	"strconv"
 	"strings"
 )
 
 type FDISK struct {
-	size   int
-	unit   string
-	fit    string
-	path   string
-	typ    string
-	name   string
-	delete string
-	add    int
+	size        int
+	unit        string
+	fit         string
+	path        string
+	typ         string
+	name        string
+	delete      string
+	add         int
+	driveLetter string
+	confirm     bool
 }
 
-func ParseFdisk(tokens []string) (string, error) {
-	cmd := &FDISK{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=\d+|-unit=[kKmMbB]|-fit=[bBfF]{2}|-driveletter=[A-Za-z]|-type=[pPeElL]|-name="[^"]+"|-name=[^\s]+|-delete=[fFuUlL]+|-add=-?\d+`)
-	matches := re.FindAllString(args, -1)
+var fdiskParams = []lexer.Param{
+	{Name: "-size", Type: lexer.Int, Pattern: `[1-9]\d*`, Help: "tamano de la particion, obligatorio al crear"},
+	{Name: "-unit", Values: []string{"B", "K", "M"}, Help: "unidad del tamano (por defecto la de la configuracion)"},
+	{Name: "-fit", Values: []string{"BF", "FF", "WF"}, Help: "ajuste (por defecto el de la configuracion)"},
+	{Name: "-path", Help: "ruta del disco"},
+	{Name: "-driveletter", Pattern: `[A-Za-z]`, Help: "letra del disco"},
+	{Name: "-type", Default: "P", Values: []string{"P", "E", "L"}, Help: "primaria, extendida o logica"},
+	{Name: "-name", Required: true, Help: "nombre de la particion"},
+	{Name: "-delete", Values: []string{"fast", "full"}, Help: "elimina la particion"},
+	{Name: "-add", Type: lexer.Int, Help: "espacio a agregar o quitar (negativo)"},
+	{Name: "-confirm", Type: lexer.Flag, Help: "elimina sin pedir confirmacion"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalid: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(&Command{
+		Name:        "fdisk",
+		Description: "Crea, elimina o cambia el tamano de una particion",
+		Params:      fdiskParams,
+		Handler:     Handle(ParseFdisk),
+	})
+}
 
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil || size <= 0 {
-				return "", err
-			}
-			cmd.size = size
-		case "-unit":
-			value = strings.ToUpper(value)
-			if value != "K" && value != "M" && value != "B" {
-				return "", errors.New("la unidad debe ser K o M o B")
-			}
-			cmd.unit = strings.ToUpper(value)
-		case "-fit":
-			value = strings.ToUpper(value)
-			if value != "BF" && value != "FF" && value != "WF" {
-				return "", errors.New("el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = value
-		case "-driveletter":
-			if value == "" {
-				return "", errors.New("el driveletter no puede estar vacío")
-			}
-			cmd.path = value
-		case "-type":
-			value = strings.ToUpper(value)
-			if value != "P" && value != "E" {
-				return "", errors.New("el tipo debe ser P o E")
-			}
-			cmd.typ = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede estar vacío")
-			}
-			cmd.name = value
-		case "-delete":
-			value = strings.ToLower(value)
-			if value != "fast" && value != "full" {
-				return "", errors.New("para -delete se debe de indicar si sera fast o full")
-			}
-			cmd.delete = value
-		case "-add":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", err
-			}
-			cmd.add = size
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
+func ParseFdisk(args lexer.Args) (string, error) {
+	cmd := &FDISK{
+		size:        args.Int("-size"),
+		unit:        strings.ToUpper(args["-unit"]),
+		fit:         strings.ToUpper(args["-fit"]),
+		path:        args["-path"],
+		driveLetter: args["-driveletter"],
+		typ:         strings.ToUpper(args["-type"]),
+		name:        args["-name"],
+		delete:      strings.ToLower(args["-delete"]),
+		add:         args.Int("-add"),
+		confirm:     args.Has("-confirm"),
 	}
 
-	if cmd.delete == "" && cmd.add == 0 {
-		if cmd.size == 0 {
-			return "", errors.New("faltan parámetros requeridos: -size")
-		}
+	if cmd.delete == "" && cmd.add == 0 && cmd.size == 0 {
+		return "", errors.New("faltan parámetros requeridos: -size")
 	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -driveletter")
-	}
-	cmd.path = stores.GetPathDisk(cmd.path)
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
+	path, err := stores.ResolveDiskPath(cmd.path, cmd.driveLetter)
+	if err != nil {
+		return "", err
 	}
-
+	cmd.path = path
 	if cmd.unit == "" {
-		cmd.unit = "K"
+		cmd.unit = config.Current.PartitionUnit
 	}
-
 	if cmd.fit == "" {
-		cmd.fit = "WF"
-	}
-
-	if cmd.typ == "" {
-		cmd.typ = "P"
+		cmd.fit = config.Current.PartitionFit
 	}
 	if cmd.delete != "" && cmd.add != 0 {
 		return "", errors.New("no se puede tener add y delete en el mismo comando")
 	}
 
 	if cmd.delete != "" {
-		outcome := askConsent()
-		if outcome {
-			err := deletePartition(cmd)
+		if !cmd.confirm {
+			outcome, err := askConsent()
 			if err != nil {
 				return "", err
 			}
-			return fmt.Sprintf("FDISK: %s delete exitosamente", cmd.name), nil
+			if !outcome {
+				return fmt.Sprintf("FDISK: %s delete cancelada exitosamente", cmd.name), nil
+			}
+		}
+		err := deletePartition(cmd)
+		if err != nil {
+			return "", err
 		}
-		return fmt.Sprintf("FDISK: %s delete cancelada exitosamente", cmd.name), nil
+		return fmt.Sprintf("FDISK: %s delete exitosamente", cmd.name), nil
 	} else if cmd.add != 0 {
 		err := addPartition(cmd)
 		if err != nil {
@@ -148,22 +112,31 @@ func ParseFdisk(tokens []string) (string, error) {
 
 }
 
-func askConsent() bool {
-	scanner := bufio.NewScanner(os.Stdin)
+// Pregunta en la consola interactiva. En el servidor, un script, -c o una
+// tuberia nadie puede responder (y las lineas siguientes no son la respuesta),
+// ahi hace falta -confirm.
+func askConsent() (bool, error) {
+	if ServerMode {
+		return false, errors.New("en el servidor fdisk -delete necesita -confirm")
+	}
+	if Terminal == nil {
+		return false, errors.New("fuera de la consola fdisk -delete necesita -confirm")
+	}
 	for {
-		fmt.Print("Desea confirmar la ejecucion del delete? [y/n]: ")
-		if !scanner.Scan() {
+		if !config.Current.JSONOutput() {
+			fmt.Print("Desea confirmar la ejecucion del delete? [y/n]: ")
+		}
+		if !Terminal.Scan() {
 			break
 		}
-		input := scanner.Text()
-		input = strings.ToLower(input)
+		input := strings.ToLower(Terminal.Text())
 		if input == "y" {
-			return true
+			return true, nil
 		} else if input == "n" {
-			return false
+			return false, nil
 		}
 	}
-	return false
+	return false, nil
 }
 
 func commandFdisk(fdisk *FDISK) error {
@@ -183,6 +156,11 @@ func commandFdisk(fdisk *FDISK) error {
 		if err != nil {
 			return err
 		}
+	} else if fdisk.typ == "L" {
+		err = createLogicalPartition(fdisk, sizeBytes)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 
@@ -195,16 +173,12 @@ func createPrimaryPartition(fdisk *FDISK, sizeBytes int) error {
 	if err != nil {
 		return err
 	}
-	if !mbr.CanFitAnotherDisk(sizeBytes) {
-		return errors.New("no se puede crear una particion por falta de espacio")
-	}
-
 	// fmt.Println("\nMBR original: ")
 	// mbr.PrintMBR()
 
-	availablePartition, startPartition, indexPartition := mbr.GetFirstAvailablePartition()
-	if availablePartition == nil {
-		return errors.New("no hay partitciones disponibles")
+	availablePartition, startPartition, indexPartition, err := mbr.GetFirstAvailablePartition(sizeBytes)
+	if err != nil {
+		return err
 	}
 
 	// fmt.Println("\nParticion disponible:")
@@ -237,10 +211,6 @@ func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 		return err
 	}
 
-	if !mbr.CanFitAnotherDisk(sizeBytes) {
-		return errors.New("no se puede crear una particion por falta de espacio")
-	}
-
 	// fmt.Println("\nMBR original: ")
 	// mbr.PrintMBR()
 
@@ -248,9 +218,9 @@ func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 		return errors.New("no se puede crear mas de 1 particion extendida por disco")
 	}
 
-	availablePartition, startPartition, indexPartition := mbr.GetFirstAvailablePartition()
-	if availablePartition == nil {
-		return errors.New("no hay partitciones disponibles")
+	availablePartition, startPartition, indexPartition, err := mbr.GetFirstAvailablePartition(sizeBytes)
+	if err != nil {
+		return err
 	}
 
 	availablePartition.CreatePartition(startPartition, sizeBytes, fdisk.typ, fdisk.fit, fdisk.name)
@@ -268,46 +238,148 @@ func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 		return err
 	}
 
+	// El primer EBR queda vacio al inicio de la extendida
+	firstEBR := &structures.EBR{Ebr_start: int32(startPartition), Ebr_next: -1}
+	firstEBR.Clean()
+	err = firstEBR.Serialize(fdisk.path, int64(startPartition))
+	if err != nil {
+		return err
+	}
+
 	return nil
 
 }
 
+func createLogicalPartition(fdisk *FDISK, sizeBytes int) error {
+	var mbr structures.MBR
+
+	err := mbr.DeserializeMBR(fdisk.path)
+	if err != nil {
+		return err
+	}
+
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return errors.New("no se puede crear una particion logica sin una particion extendida")
+	}
+	if sizeBytes <= binary.Size(structures.EBR{}) {
+		return errors.New("el tamano de la particion logica no alcanza ni para su EBR")
+	}
+
+	ebrs, err := mbr.GetEBRs(fdisk.path)
+	if err != nil {
+		return err
+	}
+	for _, ebr := range ebrs {
+		if !ebr.IsEmpty() && strings.EqualFold(ebr.GetName(), fdisk.name) {
+			return errors.New("ya existe una particion logica con ese nombre")
+		}
+	}
+	partition, _ := mbr.GetPartitionByName(fdisk.name)
+	if partition != nil {
+		return errors.New("ya existe una particion con ese nombre")
+	}
+
+	spaces, err := mbr.GetExtendedFreeSpaces(fdisk.path)
+	if err != nil {
+		return err
+	}
+	// Las logicas se acomodan con el ajuste de la extendida
+	start, err := structures.ChooseFreeSpace(spaces, int32(sizeBytes), extended.Part_fit[0])
+	if err != nil {
+		return errors.New("no hay espacio suficiente en la particion extendida")
+	}
+
+	// Si el hueco empieza en la cabecera vacia se reutiliza ese EBR
+	head := ebrs[0]
+	if head.IsEmpty() && head.Ebr_start == start {
+		head.CreateEBR(int(head.Ebr_start), sizeBytes, int(head.Ebr_next), fdisk.fit, fdisk.name)
+		return head.Serialize(fdisk.path, int64(head.Ebr_start))
+	}
+
+	// La cadena se mantiene ordenada por posicion, el nuevo EBR va despues del
+	// ultimo que empiece antes que el
+	previous := head
+	for _, ebr := range ebrs {
+		if ebr.Ebr_start < start {
+			previous = ebr
+		}
+	}
+
+	neoEBR := &structures.EBR{}
+	neoEBR.CreateEBR(int(start), sizeBytes, int(previous.Ebr_next), fdisk.fit, fdisk.name)
+	err = neoEBR.Serialize(fdisk.path, int64(start))
+	if err != nil {
+		return err
+	}
+
+	previous.Ebr_next = start
+	return previous.Serialize(fdisk.path, int64(previous.Ebr_start))
+}
+
 func deletePartition(fdisk *FDISK) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
-	var logicPartition bool
 	if err != nil {
 		return err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(fdisk.name)
 	if partition == nil {
-		partition, err = mbr.GetExtendedPartition()
+		return deleteLogicalPartition(mbr, fdisk)
+	}
+	partitionStart := partition.Part_start
+	partitionSize := partition.Part_size
+	cleanPartition := &structures.PARTITION{
+		Part_status: [1]byte{'N'}, Part_type: [1]byte{'N'}, Part_fit: [1]byte{'N'}, Part_start: -1, Part_size: -1, Part_name: [16]byte{'N'}, Part_correlative: -1, Part_id: [4]byte{'N'},
+	}
+	mbr.Mbr_partitions[indexPartition] = *cleanPartition
+	err = mbr.SerializeMBR(fdisk.path)
+	if err != nil {
+		return err
+	}
+	if fdisk.delete == "full" {
+		err := FullDeletePartition(partitionStart, partitionSize, fdisk.path)
 		if err != nil {
 			return err
 		}
-		logicPartition = true
-	}
-	var partitionStart int32
-	var partitionSize int32
-	if !logicPartition {
-		partitionStart = partition.Part_start
-		partitionSize = partition.Part_size
-		cleanPartition := &structures.PARTITION{
-			Part_status: [1]byte{'N'}, Part_type: [1]byte{'N'}, Part_fit: [1]byte{'N'}, Part_start: -1, Part_size: -1, Part_name: [16]byte{'N'}, Part_correlative: -1, Part_id: [4]byte{'N'},
+	}
+	return nil
+}
+
+func deleteLogicalPartition(mbr *structures.MBR, fdisk *FDISK) error {
+	if !mbr.IsThereExtendedPartition() {
+		return errors.New("la particion no existe")
+	}
+	ebrs, err := mbr.GetEBRs(fdisk.path)
+	if err != nil {
+		return err
+	}
+	for i, ebr := range ebrs {
+		if ebr.IsEmpty() || !strings.EqualFold(ebr.GetName(), fdisk.name) {
+			continue
+		}
+		ebrSize := int32(binary.Size(structures.EBR{}))
+		if i == 0 {
+			// El primer EBR no se puede quitar de la cadena, solo se vacia
+			ebr.Clean()
+			err = ebr.Serialize(fdisk.path, int64(ebr.Ebr_start))
+		} else {
+			previous := ebrs[i-1]
+			previous.Ebr_next = ebr.Ebr_next
+			err = previous.Serialize(fdisk.path, int64(previous.Ebr_start))
 		}
-		mbr.Mbr_partitions[indexPartition] = *cleanPartition
-		err = mbr.SerializeMBR(fdisk.path)
 		if err != nil {
 			return err
 		}
-	}
-	if fdisk.delete == "full" {
-		err := FullDeletePartition(partitionStart, partitionSize, fdisk.path)
-		if err != nil {
-			return err
+		if fdisk.delete == "full" {
+			if i == 0 {
+				return FullDeletePartition(ebr.Ebr_start+ebrSize, ebrs[0].Ebr_size-ebrSize, fdisk.path)
+			}
+			return FullDeletePartition(ebr.Ebr_start, ebr.Ebr_size, fdisk.path)
 		}
+		return nil
 	}
-	return nil
+	return errors.New("la particion no existe")
 }
 
 func FullDeletePartition(offset, amountBytes int32, diskPath string) error {
@@ -344,29 +416,35 @@ func addPartition(fdisk *FDISK) error {
 func shrinkPartition(fdisk *FDISK, sizeBytes int) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
-	var logicPartition bool
 	if err != nil {
 		return err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(fdisk.name)
 	if partition == nil {
-		partition, err = mbr.GetExtendedPartition()
+		return resizeLogicalPartition(mbr, fdisk, -sizeBytes)
+	}
+	if sizeBytes >= int(partition.Part_size) {
+		return errors.New("no se puede quitar bytes a la particion dado que quedaria en negativo el size")
+	}
+	if partition.Part_type[0] == 'E' {
+		// Las logicas deben seguir cabiendo dentro de la extendida
+		logicals, err := mbr.GetLogicalPartitions(fdisk.path)
 		if err != nil {
 			return err
 		}
-		logicPartition = true
-	}
-	if !logicPartition {
-		if sizeBytes > int(partition.Part_size) {
-			return errors.New("no se puede quitar bytes a la particion dado que quedaria en negativo el size")
+		neoEnd := partition.Part_start + partition.Part_size - int32(sizeBytes)
+		for _, logical := range logicals {
+			if logical.Ebr_start+logical.Ebr_size > neoEnd {
+				return errors.New("no se puede reducir la extendida porque dejaria fuera particiones logicas")
+			}
 		}
-		partition.Part_size = partition.Part_size - int32(sizeBytes)
+	}
+	partition.Part_size = partition.Part_size - int32(sizeBytes)
 
-		mbr.Mbr_partitions[indexPartition] = *partition
-		err = mbr.SerializeMBR(fdisk.path)
-		if err != nil {
-			return err
-		}
+	mbr.Mbr_partitions[indexPartition] = *partition
+	err = mbr.SerializeMBR(fdisk.path)
+	if err != nil {
+		return err
 	}
 	return nil
 }
@@ -374,31 +452,55 @@ func shrinkPartition(fdisk *FDISK, sizeBytes int) error {
 func increasePartition(fdisk *FDISK, sizeBytes int) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
-	var logicPartition bool
 	if err != nil {
 		return err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(fdisk.name)
 	if partition == nil {
-		partition, err = mbr.GetExtendedPartition()
-		if err != nil {
-			return err
-		}
-		logicPartition = true
+		return resizeLogicalPartition(mbr, fdisk, sizeBytes)
 	}
-	if !logicPartition {
-		outcome := isItPosibleToAdd(partition.Part_start+partition.Part_size, mbr, sizeBytes, indexPartition, mbr.Mbr_size)
-		if !outcome {
-			return errors.New("no hay suficiente espacio como para adicionar bytes a la particion")
+	outcome := isItPosibleToAdd(partition.Part_start+partition.Part_size, mbr, sizeBytes, indexPartition, mbr.Mbr_size)
+	if !outcome {
+		return errors.New("no hay suficiente espacio como para adicionar bytes a la particion")
+	}
+	partition.Part_size += int32(sizeBytes)
+	mbr.Mbr_partitions[indexPartition] = *partition
+	err = mbr.SerializeMBR(fdisk.path)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// amountOfBytes positivo agranda la logica y negativo la reduce
+func resizeLogicalPartition(mbr *structures.MBR, fdisk *FDISK, amountOfBytes int) error {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return errors.New("la particion no existe")
+	}
+	ebrs, err := mbr.GetEBRs(fdisk.path)
+	if err != nil {
+		return err
+	}
+	for _, ebr := range ebrs {
+		if ebr.IsEmpty() || !strings.EqualFold(ebr.GetName(), fdisk.name) {
+			continue
 		}
-		partition.Part_size += int32(sizeBytes)
-		mbr.Mbr_partitions[indexPartition] = *partition
-		err = mbr.SerializeMBR(fdisk.path)
-		if err != nil {
-			return err
+		neoSize := int(ebr.Ebr_size) + amountOfBytes
+		if neoSize <= binary.Size(structures.EBR{}) {
+			return errors.New("no se puede quitar bytes a la particion dado que quedaria en negativo el size")
 		}
+		limit := int(extended.Part_start + extended.Part_size)
+		if ebr.Ebr_next != -1 {
+			limit = int(ebr.Ebr_next)
+		}
+		if int(ebr.Ebr_start)+neoSize > limit {
+			return errors.New("no hay suficiente espacio como para adicionar bytes a la particion")
+		}
+		ebr.Ebr_size = int32(neoSize)
+		return ebr.Serialize(fdisk.path, int64(ebr.Ebr_start))
 	}
-	return nil
+	return errors.New("la particion no existe")
 }
 
 func isItPosibleToAdd(partitionEnd int32, mbr *structures.MBR, amountOfBytes int, indexPartition int, diskEnd int32) bool {
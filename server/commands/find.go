@@ -2,95 +2,74 @@ package commands
 
 import (
 	"errors"
-	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/reports"
+	"server/result"
 	"server/stores"
 	utils "server/utils"
 	"strings"
 )
 
 type FIND struct {
-	path string
-	name string
+	path    string
+	name    string
+	pattern string
 }
 
 // \.    .*             .{1}
-func ParseFind(tokens []string) (string, error) {
-	cmd := &FIND{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var findParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "carpeta donde empieza la busqueda"},
+	{Name: "-name", Required: true, Help: "nombre a buscar, acepta ? y *"},
+}
 
-		switch key {
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el name no puede estar vacio")
-			}
-			value = strings.ReplaceAll(value, ".", "\\.")
-			value = strings.ReplaceAll(value, "*", ".+")
-			value = strings.ReplaceAll(value, "?", ".{1}")
-			cmd.name = "^" + value + "$"
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
-	}
+func init() {
+	Register(&Command{
+		Name:        "find",
+		Description: "Busca archivos y carpetas por nombre",
+		Params:      findParams,
+		Handler:     Handle(ParseFind),
+	})
+}
 
-	result, err := commandFind(cmd)
-	if err != nil {
-		return "", err
-	}
-	fmt.Println(result)
+func ParseFind(args lexer.Args) (*result.FindHits, error) {
+	cmd := &FIND{path: args["-path"], pattern: args["-name"]}
+	value := strings.ReplaceAll(cmd.pattern, ".", "\\.")
+	value = strings.ReplaceAll(value, "*", ".+")
+	value = strings.ReplaceAll(value, "?", ".{1}")
+	cmd.name = "^" + value + "$"
 
-	return fmt.Sprintf("FIND: %s\n%s ", cmd.path, result), nil
+	return commandFind(cmd)
 }
 
-func commandFind(find *FIND) (string, error) {
+func commandFind(find *FIND) (*result.FindHits, error) {
 	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	inodoBase, offsetToSerialize, err := reports.UbicarInodo(sb, find.path, diskPath)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	outcome, err := inodoBase.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inodoBase.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if !outcome {
-		return "", errors.New("accion prohibida por falta de permisos")
+		return nil, errors.New("accion prohibida por falta de permisos")
 	}
 	tipoInodo, err := sb.TypeOfInode(diskPath, offsetToSerialize)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if tipoInodo == 1 {
-		return "", errors.New("este comando solo es aplicable a carpetas no a archivos")
+		return nil, errors.New("este comando solo es aplicable a carpetas no a archivos")
 	}
 
-	return sb.CommandFind(diskPath, offsetToSerialize, 1, find.name)
+	hits := &result.FindHits{Path: find.path, Name: find.pattern, Hits: make([]string, 0)}
+	hits.Tree, err = sb.CommandFind(diskPath, offsetToSerialize, 1, find.name, find.path, &hits.Hits)
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
 }
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/stores"
+)
+
+type FSCK struct {
+	id     string
+	repair bool
+}
+
+var fsckParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+	{Name: "-repair", Type: lexer.Flag, Help: "repara lo que se pueda"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "fsck",
+		Description: "Revisa la consistencia del sistema de archivos",
+		Params:      fsckParams,
+		Handler:     Handle(ParseFsck),
+	})
+}
+
+func ParseFsck(args lexer.Args) (string, error) {
+	cmd := &FSCK{id: args["-id"], repair: args.Has("-repair")}
+
+	problems, err := CommandFsck(cmd)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) == 0 {
+		return fmt.Sprintf("FSCK: la particion %s no tiene inconsistencias", cmd.id), nil
+	}
+	result := fmt.Sprintf("FSCK: se encontraron %d inconsistencias en la particion %s", len(problems), cmd.id)
+	if cmd.repair {
+		result += " (se aplicaron las reparaciones posibles)"
+	}
+	for _, problem := range problems {
+		result += "\n   " + problem
+	}
+	return result, nil
+}
+
+func CommandFsck(fsck *FSCK) ([]string, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(fsck.id)
+	if err != nil {
+		return nil, err
+	}
+	problems, err := sb.CheckConsistency(diskPath, fsck.repair)
+	if err != nil {
+		return nil, err
+	}
+	if fsck.repair {
+		err = sb.Serialize(diskPath, int64(partition.Part_start))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/result"
+	"strings"
+	"text/tabwriter"
+)
+
+type HELP struct {
+	command string
+}
+
+var helpParams = []lexer.Param{
+	{Name: "comando", Positional: true, Help: "comando del que se quiere ver los parametros"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "help",
+		Description: "Muestra los comandos disponibles o los parametros de uno",
+		Params:      helpParams,
+		Handler:     Handle(ParseHelp),
+	})
+}
+
+func ParseHelp(args lexer.Args) (*result.Help, error) {
+	cmd := &HELP{command: strings.ToLower(args["comando"])}
+
+	if cmd.command == "" {
+		return &result.Help{Text: commandList()}, nil
+	}
+	command, exists := Lookup(cmd.command)
+	if !exists {
+		return nil, unknownCommand(cmd.command)
+	}
+	return &result.Help{Command: command.Name, Text: commandUsage(command)}, nil
+}
+
+func commandList() string {
+	var sb strings.Builder
+	sb.WriteString("Comandos disponibles:\n")
+	writer := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, command := range Registered() {
+		fmt.Fprintf(writer, "  %s\t%s\n", command.Name, command.Description)
+	}
+	writer.Flush()
+	sb.WriteString("Use help <comando> para ver sus parametros")
+	return sb.String()
+}
+
+// Uso del comando armado con los parametros que declara, los opcionales van
+// entre corchetes
+func commandUsage(command *Command) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s\n", command.Name, command.Description)
+
+	usage := []string{command.Name}
+	for _, param := range command.Params {
+		text := param.Usage()
+		if param.Type != lexer.Flag && !param.Positional {
+			text += "=<" + typeName(param.Type) + ">"
+		}
+		if !param.Required {
+			text = "[" + text + "]"
+		}
+		usage = append(usage, text)
+	}
+	fmt.Fprintf(&sb, "Uso: %s", strings.Join(usage, " "))
+	if len(command.Params) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\nParametros:\n")
+	writer := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, param := range command.Params {
+		details := []string{typeName(param.Type)}
+		if param.Required {
+			details = append(details, "obligatorio")
+		}
+		if param.Default != "" {
+			details = append(details, "por defecto "+param.Default)
+		}
+		if len(param.Values) > 0 {
+			details = append(details, "valores: "+strings.Join(param.Values, ", "))
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\n", param.Usage(), strings.Join(details, ", "), param.Help)
+	}
+	writer.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func typeName(paramType lexer.ParamType) string {
+	switch paramType {
+	case lexer.Int:
+		return "entero"
+	case lexer.Flag:
+		return "bandera"
+	}
+	return "texto"
+}
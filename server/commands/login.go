@@ -4,10 +4,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	utils "server/utils"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,57 +20,32 @@ type LOGIN struct {
 	Id       string
 }
 
-func ParseLogin(tokens []string) (string, error) {
-	cmd := &LOGIN{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-user="[^"]+"|-user=[^\s]+|-pass="[^"]+"|-pass=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var loginParams = []lexer.Param{
+	{Name: "-user", Required: true, Help: "usuario"},
+	{Name: "-pass", Required: true, Help: "contrasena"},
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(&Command{
+		Name:        "login",
+		Description: "Inicia sesion en una particion",
+		Params:      loginParams,
+		Handler:     Handle(ParseLogin),
+	})
+}
 
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.Id = value
-		case "-pass":
-			if value == "" {
-				return "", errors.New("el password no puede estar vacio")
-			}
-			cmd.Password = value
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			cmd.User = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.Password == "" {
-		return "", errors.New("faltan parametros requeridos: -pass")
-	}
-	if cmd.User == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
-	}
-	if cmd.Id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
-	}
+func ParseLogin(args lexer.Args) (string, error) {
+	cmd := &LOGIN{User: args["-user"], Password: args["-pass"], Id: args["-id"]}
 
 	err := CommandLogin(cmd)
 	if err != nil {
 		return "", err
 	}
+	err = stores.SaveState()
+	if err != nil {
+		return "", err
+	}
 
 	return fmt.Sprintf("LOGIN: %s logeado exitosamente", cmd.User), nil
 
@@ -85,17 +61,34 @@ func CommandLogin(login *LOGIN) error {
 	}
 	contentMatrix := getContentMatrixUsers(contentUsersTxt)
 
-	credentials := validateInformation(login.User, login.Password, contentMatrix)
-	if !credentials {
+	row := validateInformation(login.User, login.Password, contentMatrix)
+	if row == nil {
 		return errors.New("credenciales invalidas en el login")
 	}
-	stores.LogedIdPartition = login.Id
-	stores.LogedUser = login.User
-	err = setUpIDs(login.User, contentMatrix)
+	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(login.Id)
 	if err != nil {
 		return err
 	}
-	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if sb.IsExt3() {
+		entries := 1
+		if !utils.IsHashedPassword(row[4]) {
+			entries++
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))), entries)
+		if err != nil {
+			return err
+		}
+	}
+	// Las contraseñas en texto plano se cambian por bcrypt al entrar
+	if !utils.IsHashedPassword(row[4]) {
+		err = setUserPassword(sb, part, diskPath, contentMatrix, row, login.Password)
+		if err != nil {
+			return err
+		}
+	}
+	stores.LogedIdPartition = login.Id
+	stores.LogedUser = login.User
+	err = setUpIDs(login.User, contentMatrix)
 	if err != nil {
 		return err
 	}
@@ -109,7 +102,7 @@ func CommandLogin(login *LOGIN) error {
 				I_date:      float32(time.Now().Unix()),
 			},
 		}
-		fullContent := fmt.Sprintf("%s/%s/%s", login.Id, login.User, login.Password)
+		fullContent := fmt.Sprintf("%s/%s", login.Id, login.User)
 		copy(journalDirectory.J_content.I_content[:], fullContent)
 		err = sb.AddJournal(journalDirectory, diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))))
 		if err != nil {
@@ -139,16 +132,16 @@ func splitContent(content string, parameter string) []string {
 	return result
 }
 
-func validateInformation(user, password string, matrix [][]string) bool {
+func validateInformation(user, password string, matrix [][]string) []string {
 	for _, row := range matrix {
 		if row[1] != "U" {
 			continue
 		}
-		if row[3] == user && row[4] == password {
-			return true
+		if row[3] == user && utils.CheckPassword(row[4], password) {
+			return row
 		}
 	}
-	return false
+	return nil
 }
 
 func getContentMatrixUsers(contentUsers string) [][]string {
@@ -163,6 +156,7 @@ func getContentMatrixUsers(contentUsers string) [][]string {
 
 func setUpIDs(userName string, matrix [][]string) error {
 	var nameGroup string
+	var extraGroups []string
 	for _, row := range matrix {
 		if row[1] != "U" {
 			continue
@@ -175,6 +169,7 @@ func setUpIDs(userName string, matrix [][]string) error {
 			utils.LogedUserID = int32(num)
 
 			nameGroup = row[2]
+			extraGroups = getExtraGroups(row)
 			break
 		}
 	}
@@ -191,5 +186,16 @@ func setUpIDs(userName string, matrix [][]string) error {
 			break
 		}
 	}
+	utils.LogedUserExtraGroupIDs = nil
+	for _, row := range matrix {
+		if row[1] != "G" || row[0] == "0" || !slices.Contains(extraGroups, row[2]) {
+			continue
+		}
+		num, err := strconv.Atoi(row[0])
+		if err != nil {
+			return err
+		}
+		utils.LogedUserExtraGroupIDs = append(utils.LogedUserExtraGroupIDs, int32(num))
+	}
 	return nil
 }
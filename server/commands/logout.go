@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"time"
+)
+
+func init() {
+	Register(&Command{
+		Name:        "logout",
+		Description: "Cierra la sesion actual",
+		Handler:     Handle(ParseLogout),
+	})
+}
+
+func ParseLogout(args lexer.Args) (string, error) {
+	if stores.LogedIdPartition == "" {
+		return "", errors.New("no hay sesion iniciada como para hacer un logout")
+	}
+	stores.LogedUser = ""
+	temp := stores.LogedIdPartition
+	stores.LogedIdPartition = ""
+	utils.LogedUserGroupID = 1
+	utils.LogedUserExtraGroupIDs = nil
+	utils.LogedUserID = 1
+	err := stores.SaveState()
+	if err != nil {
+		return "", err
+	}
+
+	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(temp)
+	if err != nil {
+		return "", err
+	}
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'l', 'o', 'g', 'o', 'u', 't'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		err = sb.AddJournal(journalDirectory, diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return "", err
+		}
+	}
+	return "LOGOUT", nil
+}
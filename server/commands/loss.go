@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"server/lexer"
+	"server/stores"
+)
+
+type LOSS struct {
+	id string
+}
+
+var lossParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "loss",
+		Description: "Simula la perdida del sistema de archivos de una particion EXT3",
+		Params:      lossParams,
+		Handler:     Handle(ParseLoss),
+	})
+}
+
+func ParseLoss(args lexer.Args) (string, error) {
+	cmd := &LOSS{id: args["-id"]}
+
+	err := CommandLoss(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("LOSS: se simulo la perdida de informacion en la particion %s", cmd.id), nil
+}
+
+// Simula un fallo: deja en cero los bitmaps, los inodos y los bloques pero
+// conserva el superbloque y el journal para poder usar recovery
+func CommandLoss(loss *LOSS) error {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(loss.id)
+	if err != nil {
+		return err
+	}
+	if !sb.IsExt3() {
+		return errors.New("la particion no es EXT3, no tiene journaling")
+	}
+	return zeroPartitionArea(diskPath, int64(sb.S_bm_inode_start), int64(partition.Part_start+partition.Part_size))
+}
+
+func zeroPartitionArea(diskPath string, start, end int64) error {
+	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 1024*1024)
+	for offset := start; offset < end; offset += int64(len(buffer)) {
+		size := end - offset
+		if size > int64(len(buffer)) {
+			size = int64(len(buffer))
+		}
+		_, err = file.WriteAt(buffer[:size], offset)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
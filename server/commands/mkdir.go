@@ -2,13 +2,11 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
 	utils "server/utils"
-	"strings"
 	"time"
 )
 
@@ -17,45 +15,22 @@ type MKDIR struct {
 	p    bool
 }
 
-func ParseMkdir(tokens []string) (string, error) {
-	cmd := &MKDIR{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-r`)
-	matches := re.FindAllString(args, -1)
-
-	if len(matches) != len(tokens) {
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
+var mkdirParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta de la carpeta"},
+	{Name: "-r", Type: lexer.Flag, Help: "crea las carpetas padre que falten"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
-
-		switch key {
-		case "-path":
-			if len(kv) != 2 {
-				return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-			}
-			value := kv[1]
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.path = value
-		case "-r":
-			cmd.p = true
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "mkdir",
+		Description: "Crea una carpeta",
+		Params:      mkdirParams,
+		Handler:     Handle(ParseMkdir),
+	})
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
-	}
+func ParseMkdir(args lexer.Args) (string, error) {
+	cmd := &MKDIR{path: args["-path"], p: args.Has("-r")}
 
 	err := CommandMkdir(cmd)
 	if err != nil {
@@ -74,6 +49,16 @@ func CommandMkdir(mkdir *MKDIR) error {
 	if err != nil {
 		return fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
+	if partitionSuperblock.IsExt3() {
+		err = structures.CheckJournalFields(mkdir.path, "")
+		if err != nil {
+			return err
+		}
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
 
 	err = createDirectory(mkdir.path, partitionSuperblock, partitionPath, mountedPartition, mkdir.p)
 	if err != nil {
@@ -6,80 +6,81 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"server/config"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
 	utils "server/utils"
-	"strconv"
 	"time"
 
-	"regexp"
 	"strings"
 )
 
 type MKDISK struct {
-	size int
-	unit string
-	fit  string
-	path string
+	size        int
+	unit        string
+	fit         string
+	path        string
+	driveLetter string
 }
 
-func ParseMkdisk(tokens []string) (string, error) {
-	cmd := &MKDISK{}
-	letterDisk := utils.GetLetterToDisk()
-	cmd.path = stores.GetPathDisk(letterDisk)
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=\d+|-unit=[kKmM]|-fit=[bBfFwW]{2}`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var mkdiskParams = []lexer.Param{
+	{Name: "-size", Type: lexer.Int, Required: true, Pattern: `[1-9]\d*`, Help: "tamano del disco"},
+	{Name: "-unit", Values: []string{"K", "M"}, Help: "unidad del tamano (por defecto la de la configuracion)"},
+	{Name: "-fit", Values: []string{"BF", "FF", "WF"}, Help: "ajuste de las particiones (por defecto el de la configuracion)"},
+	{Name: "-path", Help: "ruta del disco"},
+	{Name: "-driveletter", Pattern: `[A-Za-z]`, Help: "letra del disco dentro de la carpeta de discos"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(&Command{
+		Name:        "mkdisk",
+		Description: "Crea un disco virtual",
+		Params:      mkdiskParams,
+		Handler:     Handle(ParseMkdisk),
+	})
+}
 
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", errors.New("el tamano debe ser numero entero positivo")
-			}
-			cmd.size = size
-		case "-unit":
-			value = strings.ToUpper(value)
-			if value != "K" && value != "M" {
-				return "", errors.New("la unidd debe ser K o M")
-			}
-			cmd.unit = value
-		case "-fit":
-			value = strings.ToUpper(value)
-			if value != "BF" && value != "FF" && value != "WF" {
-				return "", errors.New("el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
+func ParseMkdisk(args lexer.Args) (string, error) {
+	cmd := &MKDISK{
+		size:        args.Int("-size"),
+		unit:        strings.ToUpper(args["-unit"]),
+		fit:         strings.ToUpper(args["-fit"]),
+		path:        args["-path"],
+		driveLetter: args["-driveletter"],
 	}
 
-	if cmd.size == 0 {
-		return "", errors.New("faltan parametros requeridos: -size")
-	}
 	if cmd.unit == "" {
-		cmd.unit = "K"
+		cmd.unit = config.Current.DiskUnit
 	}
 	if cmd.fit == "" {
-		cmd.fit = "FF"
+		cmd.fit = config.Current.DiskFit
+	}
+	if cmd.path == "" && cmd.driveLetter == "" {
+		path, err := stores.NextDiskPath()
+		if err != nil {
+			return "", err
+		}
+		cmd.path = path
+	} else {
+		path, err := stores.ResolveDiskPath(cmd.path, cmd.driveLetter)
+		if err != nil {
+			return "", err
+		}
+		if fileExists(path) {
+			return "", fmt.Errorf("ya existe un disco en %s", path)
+		}
+		cmd.path = path
 	}
 	err := commandMkdisk(cmd)
 	if err != nil {
 		return "", err
 	}
-	name := utils.GetNameByPath(cmd.path)
-	stores.LoadedDiskPaths[name] = cmd.path
+	stores.RegisterDisk(cmd.path)
+	err = stores.SaveState()
+	if err != nil {
+		return "", err
+	}
 	return fmt.Sprintf("MKDISK: %s creado exitosamente", cmd.path), nil
 
 }
@@ -2,14 +2,12 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
 	"server/utils"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -21,55 +19,28 @@ type MKFILE struct {
 	cont string
 }
 
-func ParseMkfile(tokens []string) (string, error) {
-	cmd := &MKFILE{}
-	cmd.size = 0
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=-?\d+|-r|-path="[^"]+"|-path=[^\s]+|-cont="[^"]+"|-cont=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-
-		if match == "-r" {
-			cmd.r = true
-			continue
-		}
-
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var mkfileParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta del archivo"},
+	{Name: "-size", Type: lexer.Int, Pattern: `\d+`, Help: "tamano en bytes, se llena con 0123456789..."},
+	{Name: "-cont", Help: "archivo de la computadora con el contenido"},
+	{Name: "-r", Type: lexer.Flag, Help: "crea las carpetas padre que falten"},
+}
 
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", errors.New("el tamano debe ser numero entero positivo")
-			}
-			if size <= -1 {
-				return "", errors.New("no puede ser un numero negativo")
-			}
-			cmd.size = size
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-cont":
-			if value == "" {
-				return "", errors.New("el cont no puede estar vacio")
-			}
-			cmd.cont = value
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "mkfile",
+		Description: "Crea un archivo",
+		Params:      mkfileParams,
+		Handler:     Handle(ParseMkfile),
+	})
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -path")
+func ParseMkfile(args lexer.Args) (string, error) {
+	cmd := &MKFILE{
+		path: args["-path"],
+		size: args.Int("-size"),
+		cont: args["-cont"],
+		r:    args.Has("-r"),
 	}
 
 	err := CommandMkfile(cmd)
@@ -86,6 +57,12 @@ func CommandMkfile(mkfile *MKFILE) error {
 	if err != nil {
 		return err
 	}
+	if partitionSuperblock.IsExt3() {
+		err = structures.CheckJournalFields(mkfile.path, "")
+		if err != nil {
+			return err
+		}
+	}
 	err = createFile(partitionPath, partitionSuperblock, mountedPartition, mkfile.path, mkfile.r, mkfile.size, mkfile.cont)
 	if err != nil {
 		return err
@@ -98,42 +75,37 @@ func createFile(diskPath string, sb *structures.SuperBlock, partition *structure
 	if sizeFile < 0 {
 		return fmt.Errorf("no puede venir un size negativo")
 	}
-	if createDir {
-		position := strings.LastIndex(filePath, "/")
-		dirPath := filePath[:position]
-		parentDirs, destDir := utils.GetParentDirectories(dirPath)
-		err := sb.CreateFolder(diskPath, parentDirs, destDir, true)
-		if err != nil {
-			return err
-		}
-	}
 	if pathFileToGetInfo != "" {
 		fileContent, err := os.ReadFile(pathFileToGetInfo)
 		if err != nil {
 			return err
 		}
 		contentToWrite = string(fileContent)
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err = sb.CreateFile(diskPath, 0, parentDirs, destDir, string(fileContent), int32(len(fileContent)), false)
-		if err != nil {
-			return err
-		}
 	} else if sizeFile > 0 {
-		content := getStringContent(sizeFile)
-		contentToWrite = content
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err := sb.CreateFile(diskPath, 0, parentDirs, destDir, content, int32(sizeFile), false)
+		contentToWrite = getStringContent(sizeFile)
+	}
+	if sb.IsExt3() {
+		// Una entrada por cada parte de 64 bytes del contenido, o una si esta vacio
+		entries := max(len(utils.SplitStringIntoChunks(contentToWrite)), 1)
+		err := sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), entries)
 		if err != nil {
 			return err
 		}
-	} else {
-		contentToWrite = ""
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err := sb.CreateFile(diskPath, 0, parentDirs, destDir, "", int32(0), false)
+	}
+	if createDir {
+		position := strings.LastIndex(filePath, "/")
+		dirPath := filePath[:position]
+		parentDirs, destDir := utils.GetParentDirectories(dirPath)
+		err := sb.CreateFolder(diskPath, parentDirs, destDir, true)
 		if err != nil {
 			return err
 		}
 	}
+	parentDirs, destDir := utils.GetParentDirectories(filePath)
+	err := sb.CreateFile(diskPath, 0, parentDirs, destDir, contentToWrite, int32(len(contentToWrite)), false)
+	if err != nil {
+		return err
+	}
 
 	if sb.IsExt3() {
 		if contentToWrite != "" {
@@ -174,7 +146,7 @@ func createFile(diskPath string, sb *structures.SuperBlock, partition *structure
 		}
 	}
 
-	err := sb.Serialize(diskPath, int64(partition.Part_start))
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
 	if err != nil {
 		return err
 	}
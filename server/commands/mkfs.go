@@ -2,10 +2,9 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"math"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
 	"strings"
@@ -18,51 +17,24 @@ type MKFS struct {
 	fs  string
 }
 
-func ParseMkfs(tokens []string) (string, error) {
-	cmd := &MKFS{}
+var mkfsParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+	{Name: "-type", Values: []string{"full"}, Help: "formateo completo"},
+	{Name: "-fs", Default: "2fs", Values: []string{"2fs", "3fs"}, Help: "sistema de archivos"},
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-type=[fFuUlL]+|-fs=[23]fs`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func init() {
+	Register(&Command{
+		Name:        "mkfs",
+		Description: "Formatea una particion montada",
+		Params:      mkfsParams,
+		Handler:     Handle(ParseMkfs),
+	})
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func ParseMkfs(args lexer.Args) (string, error) {
+	cmd := &MKFS{id: args["-id"], typ: args.Has("-type"), fs: strings.ToLower(args["-fs"])}
 
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		case "-type":
-			value = strings.ToLower(value)
-			if value != "full" {
-				return "", errors.New("solo se acepta el tipo full")
-			}
-			cmd.typ = true
-		case "-fs":
-			if value != "2fs" && value != "3fs" {
-				return "", errors.New("el sistema de archivos debe ser 2fs o 3fs")
-			}
-
-			cmd.fs = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
-	}
-	if cmd.fs == "" {
-		cmd.fs = "2fs"
-	}
 	err := commandMkfs(cmd)
 	if err != nil {
 		return "", err
@@ -163,6 +135,8 @@ func createSuperBlock(partition *structures.PARTITION, n int32, fs string) *stru
 		S_bm_block_start:    bm_block_start,
 		S_inode_start:       inode_start,
 		S_block_start:       block_start,
+		S_fit:               partition.Part_fit,
+		S_version:           structures.LayoutVersion,
 	}
 	return superBlock
 }
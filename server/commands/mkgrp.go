@@ -4,11 +4,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
-	"strings"
 	"time"
 )
 
@@ -16,38 +14,21 @@ type MKGRP struct {
 	name string
 }
 
-func ParseMkgrp(tokens []string) (string, error) {
-	cmd := &MKGRP{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var mkgrpParams = []lexer.Param{
+	{Name: "-name", Required: true, Help: "nombre del grupo"},
+}
 
-		switch key {
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede venir vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "mkgrp",
+		Description: "Crea un grupo",
+		Params:      mkgrpParams,
+		Handler:     Handle(ParseMkgrp),
+	})
+}
 
-	if cmd.name == "" {
-		return "", errors.New("parametro obligatorio: -name")
-	}
+func ParseMkgrp(args lexer.Args) (string, error) {
+	cmd := &MKGRP{name: args["-name"]}
 
 	err := CommmandMkgrp(cmd)
 	if err != nil {
@@ -79,6 +60,12 @@ func CommmandMkgrp(mkgrp *MKGRP) error {
 	if err != nil {
 		return err
 	}
+	if partitionSuperblock.IsExt3() {
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
 	err = OverrideUserstxt(partitionSuperblock, partitionPath, contentUsersTxt)
 	if err != nil {
 		return err
@@ -138,56 +125,6 @@ func getNeoNumber(tipo string, matrix [][]string) int {
 }
 
 func OverrideUserstxt(sb *structures.SuperBlock, diskPath, content string) error {
-	inode := &structures.Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size))
-	if err != nil {
-		return err
-	}
-	contentChunks := utils.SplitStringIntoChunks(content)
-	for i, indexFileBlock := range inode.I_block {
-		if len(contentChunks) == 0 {
-			break
-		}
-		if indexFileBlock != -1 {
-			fileBlock := &structures.FileBlock{}
-			err := fileBlock.Deserialize(diskPath, int64(sb.S_block_start+(indexFileBlock*sb.S_block_size)))
-			if err != nil {
-				return err
-			}
-			copy(fileBlock.B_content[:], []byte(contentChunks[0]))
-			contentChunks = utils.RemoveElement(contentChunks, 0)
-			fileBlock.Serialize(diskPath, int64(sb.S_block_start+(indexFileBlock*sb.S_block_size)))
-		} else {
-			if len(contentChunks) == 0 {
-				break
-			}
-			inode.I_block[i] = sb.S_blocks_count
-			contentBlock := &structures.FileBlock{
-				B_content: [64]byte{},
-			}
-			copy(contentBlock.B_content[:], []byte(contentChunks[0]))
-			contentChunks = utils.RemoveElement(contentChunks, 0)
-			err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-			if err != nil {
-				return err
-			}
-
-			err = sb.UpdateBitmapBlock(diskPath)
-			if err != nil {
-				return err
-			}
-			// fmt.Println("EL CONTADOR:", sb.S_blocks_count)
-			sb.S_blocks_count++
-			// fmt.Println("EL CONTADOR NUEVO:", sb.S_blocks_count)
-			sb.S_free_blocks_count--
-			sb.S_first_blo += sb.S_block_size
-
-		}
-	}
-	inode.I_size = int32(len(content))
-	err = inode.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size))
-	if err != nil {
-		return err
-	}
-	return nil
+	// users.txt siempre es el inodo 1
+	return sb.RewriteFile(diskPath, 1, content)
 }
@@ -4,10 +4,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
-	"strings"
+	utils "server/utils"
 	"time"
 )
 
@@ -17,63 +17,23 @@ type MKUSR struct {
 	group    string
 }
 
-func ParseMkusr(tokens []string) (string, error) {
-	cmd := &MKUSR{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-user="[^"]+"|-user=[^\s]+|-grp="[^"]+"|-grp=[^\s]+|-pass="[^"]+"|-pass=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var mkusrParams = []lexer.Param{
+	{Name: "-user", Required: true, MaxLen: 10, Help: "nombre del usuario"},
+	{Name: "-pass", Required: true, MaxLen: 10, Help: "contrasena"},
+	{Name: "-grp", Required: true, MaxLen: 10, Help: "grupo principal"},
+}
 
-		switch key {
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el user de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.user = value
-		case "-pass":
-			if value == "" {
-				return "", errors.New("el password no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el pass de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.password = value
-		case "-grp":
-			if value == "" {
-				return "", errors.New("el grp no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el group de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.group = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "mkusr",
+		Description: "Crea un usuario",
+		Params:      mkusrParams,
+		Handler:     Handle(ParseMkusr),
+	})
+}
 
-	if cmd.password == "" {
-		return "", errors.New("faltan parametros requeridos: -pass")
-	}
-	if cmd.user == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
-	}
-	if cmd.group == "" {
-		return "", errors.New("faltan parametros requeridos: -grp")
-	}
+func ParseMkusr(args lexer.Args) (string, error) {
+	cmd := &MKUSR{user: args["-user"], password: args["-pass"], group: args["-grp"]}
 
 	err := CommandMkusr(cmd)
 	if err != nil {
@@ -104,11 +64,21 @@ func CommandMkusr(mkusr *MKUSR) error {
 		return errors.New("nombre de usuario no disponible")
 	}
 	neoUserID := getNeoNumber("U", contentMatrix)
-	contentUsersTxt += fmt.Sprintf("%d,U,%s,%s,%s\n", neoUserID, mkusr.group, mkusr.user, mkusr.password)
+	hash, err := utils.HashPassword(mkusr.password)
+	if err != nil {
+		return err
+	}
+	contentUsersTxt += fmt.Sprintf("%d,U,%s,%s,%s\n", neoUserID, mkusr.group, mkusr.user, hash)
 	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
 	if err != nil {
 		return err
 	}
+	if partitionSuperblock.IsExt3() {
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
 	// fmt.Println("EL CONTADOR:", partitionSuperblock.S_blocks_count)
 	err = OverrideUserstxt(partitionSuperblock, partitionPath, contentUsersTxt)
 	if err != nil {
@@ -129,7 +99,7 @@ func CommandMkusr(mkusr *MKUSR) error {
 				I_date:      float32(time.Now().Unix()),
 			},
 		}
-		fullContent := fmt.Sprintf("%s/%s/%s", mkusr.user, mkusr.password, mkusr.group)
+		fullContent := fmt.Sprintf("%s/%s", mkusr.user, mkusr.group)
 		copy(journalDirectory.J_content.I_content[:], fullContent)
 		err = partitionSuperblock.AddJournal(journalDirectory, partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
 		if err != nil {
@@ -3,7 +3,9 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"server/config"
+	"server/lexer"
+	"server/result"
 	"server/stores"
 	"server/structures"
 	"server/utils"
@@ -16,80 +18,84 @@ type MOUNT struct {
 	driveLetter string
 }
 
-func ParseMount(tokens []string) (string, error) {
-	cmd := &MOUNT{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-driveletter=[A-Za-z]|-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-		switch key {
-		case "-driveletter":
-			if value == "" {
-				return "", errors.New("el driveletter no puede estar vacio")
-			}
-			cmd.path = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede estar vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -driveletter")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
-	}
-	cmd.driveLetter = strings.ToUpper(cmd.path)
-	cmd.path = stores.GetPathDisk(cmd.driveLetter)
-	err := commandMount(cmd)
+var mountParams = []lexer.Param{
+	{Name: "-path", Help: "ruta del disco"},
+	{Name: "-driveletter", Pattern: `[A-Za-z]`, Help: "letra del disco"},
+	{Name: "-name", Required: true, Help: "nombre de la particion"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "mount",
+		Description: "Monta una particion y le asigna un id",
+		Params:      mountParams,
+		Handler:     Handle(ParseMount),
+	})
+}
+
+func ParseMount(args lexer.Args) (*result.PartitionInfo, error) {
+	cmd := &MOUNT{
+		path:        args["-path"],
+		driveLetter: strings.ToUpper(args["-driveletter"]),
+		name:        args["-name"],
+	}
+
+	path, err := stores.ResolveDiskPath(cmd.path, cmd.driveLetter)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	cmd.path = path
+	idPartition, err := commandMount(cmd)
+	if err != nil {
+		return nil, err
+	}
+	err = stores.SaveState()
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("MOUNT: %s montada exitosamente", cmd.name), nil
+	partition, _, err := stores.GetMountedPartition(idPartition)
+	if err != nil {
+		return nil, err
+	}
+	return &result.PartitionInfo{
+		ID:      idPartition,
+		Disk:    cmd.path,
+		Name:    cmd.name,
+		Type:    string(partition.Part_type[:]),
+		Fit:     string(partition.Part_fit[:]),
+		Start:   partition.Part_start,
+		Size:    partition.Part_size,
+		Mounted: true,
+	}, nil
 }
-func commandMount(mount *MOUNT) error {
+
+func commandMount(mount *MOUNT) (string, error) {
 	var mbr structures.MBR
 
 	err := mbr.DeserializeMBR(mount.path)
 	if err != nil {
-		return err
+		return "", err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(mount.name)
 	if partition == nil {
-		return errors.New("la particion no existe")
+		return mountLogicalPartition(&mbr, mount)
 	}
 
 	// fmt.Println("\nPartición disponible:")
 	// partition.PrintPartition()
 
 	if partition.Part_status[0] == '1' {
-		return errors.New("no se puede montar una particion ya montada")
+		return "", errors.New("no se puede montar una particion ya montada")
 	}
 
 	if partition.Part_type[0] == 'E' {
-		return errors.New("no se puede montar una particion extendida")
+		return "", errors.New("no se puede montar una particion extendida")
 	}
 
 	idPartition, err := generatePartitionID(mount)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	stores.MountedPartitions[idPartition] = mount.path
@@ -102,18 +108,56 @@ func commandMount(mount *MOUNT) error {
 
 	err = mbr.SerializeMBR(mount.path)
 	if err != nil {
-		return err
+		return "", err
+	}
+	return idPartition, nil
+}
+
+func mountLogicalPartition(mbr *structures.MBR, mount *MOUNT) (string, error) {
+	if !mbr.IsThereExtendedPartition() {
+		return "", errors.New("la particion no existe")
+	}
+	ebr, err := mbr.GetLogicalPartitionByName(mount.path, mount.name)
+	if err != nil {
+		return "", errors.New("la particion no existe")
+	}
+	if ebr.Ebr_status[0] == '1' {
+		return "", errors.New("no se puede montar una particion ya montada")
+	}
+
+	idPartition, err := generatePartitionID(mount)
+	if err != nil {
+		return "", err
 	}
-	return nil
+
+	stores.MountedPartitions[idPartition] = mount.path
+	ebr.MountPartition(idPartition)
+
+	return idPartition, ebr.Serialize(mount.path, int64(ebr.Ebr_start))
 }
 
 func generatePartitionID(mount *MOUNT) (string, error) {
-	_, partitionCorrelative, err := utils.GetLetter(mount.path)
+	// Con -driveletter el id conserva la letra del disco y queda registrada
+	// para ese archivo, con -path se usa la letra asignada a ese archivo
+	if mount.driveLetter != "" {
+		utils.SetLetter(mount.path, mount.driveLetter)
+	}
+	letter, err := utils.GetLetter(mount.path)
 	if err != nil {
 		return "", err
 	}
-
-	idPartition := fmt.Sprintf("%s%d%s", mount.driveLetter, partitionCorrelative, stores.Carnet)
+	// Se usa el correlativo mas bajo que no este montado, asi los de las
+	// particiones desmontadas se vuelven a usar sin repetir un id
+	correlative := 1
+	idPartition := fmt.Sprintf("%s%d%s", letter, correlative, config.Current.IDSuffix)
+	for stores.MountedPartitions[idPartition] != "" {
+		correlative++
+		idPartition = fmt.Sprintf("%s%d%s", letter, correlative, config.Current.IDSuffix)
+	}
+	// Part_id y Ebr_id son de 4 bytes, un id mas largo quedaria cortado
+	if len(idPartition) > len(structures.PARTITION{}.Part_id) {
+		return "", fmt.Errorf("el id %s no cabe en 4 caracteres, no se pueden montar mas particiones de este disco", idPartition)
+	}
 
 	return idPartition, nil
 
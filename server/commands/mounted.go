@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"maps"
+	"server/lexer"
+	"server/result"
+	"server/stores"
+	"slices"
+	"strings"
+)
+
+func init() {
+	Register(&Command{
+		Name:        "mounted",
+		Description: "Lista las particiones montadas",
+		Handler:     Handle(ParseMounted),
+	})
+}
+
+func ParseMounted(args lexer.Args) (*result.MountedList, error) {
+	mounted := &result.MountedList{Partitions: make([]result.MountedPartition, 0)}
+	for _, id := range slices.Sorted(maps.Keys(stores.MountedPartitions)) {
+		partition, path, err := stores.GetMountedPartition(id)
+		if err != nil {
+			return nil, err
+		}
+		mounted.Partitions = append(mounted.Partitions, result.MountedPartition{
+			ID:   id,
+			Disk: path,
+			Name: strings.TrimRight(string(partition.Part_name[:]), "\x00"),
+		})
+	}
+	return mounted, nil
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"time"
+)
+
+type MOVE struct {
+	path    string
+	destino string
+}
+
+var moveParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta a mover"},
+	{Name: "-destino", Required: true, Help: "carpeta destino"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "move",
+		Description: "Mueve un archivo o carpeta",
+		Params:      moveParams,
+		Handler:     Handle(ParseMove),
+	})
+}
+
+func ParseMove(args lexer.Args) (string, error) {
+	cmd := &MOVE{path: args["-path"], destino: args["-destino"]}
+
+	err := CommandMove(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("MOVE: %s movido a %s exitosamente", cmd.path, cmd.destino), nil
+}
+
+func CommandMove(move *MOVE) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(move.path, move.destino)
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyMove(sb, diskPath, move.path, move.destino)
+	if err != nil {
+		return err
+	}
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'m', 'o', 'v', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], move.path)
+		copy(journalDirectory.J_content.I_content[:], move.destino)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Solo cambia la entrada de carpeta: el inodo y sus bloques se quedan donde estan
+func applyMove(sb *structures.SuperBlock, diskPath, path, destino string) error {
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	if inodeIndex == 0 {
+		return errors.New("no se puede mover la carpeta raiz")
+	}
+	err = sb.MoveTreePermissions(diskPath, inodeIndex, utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	parentInode, parentIndex, err := reports.UbicarInodo(sb, getParentPath(path), diskPath)
+	if err != nil {
+		return err
+	}
+	outcome, err := parentInode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("no tiene permisos de escritura en la carpeta de origen")
+	}
+	destIndex, name, err := resolveDestination(sb, diskPath, path, destino)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] == '0' {
+		inside, err := sb.IsInSubtree(diskPath, inodeIndex, destIndex)
+		if err != nil {
+			return err
+		}
+		if inside {
+			return errors.New("no se puede mover una carpeta dentro de si misma")
+		}
+	}
+
+	err = sb.RemoveEntryFromFolder(diskPath, parentIndex, name)
+	if err != nil {
+		return err
+	}
+	err = sb.AddEntryToFolder(diskPath, destIndex, name, inodeIndex)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] == '0' {
+		return sb.SetFolderParent(diskPath, inodeIndex, destIndex)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"server/config"
+	"server/lexer"
+	"strings"
+)
+
+type OUTPUT struct {
+	format string
+}
+
+var outputParams = []lexer.Param{
+	{Name: "-format", Required: true, Values: []string{"text", "json"}, Help: "formato de salida"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "output",
+		Description: "Cambia el formato de salida",
+		Params:      outputParams,
+		Handler:     Handle(ParseOutput),
+	})
+}
+
+func ParseOutput(args lexer.Args) (string, error) {
+	cmd := &OUTPUT{format: strings.ToLower(args["-format"])}
+
+	// Aplica desde el siguiente comando, incluido el resto de un script
+	config.Current.Output = cmd.format
+	return fmt.Sprintf("OUTPUT: formato de salida cambiado a %s exitosamente", cmd.format), nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	stores "server/stores"
+	"server/structures"
+	utils "server/utils"
+	"time"
+)
+
+type PASSWD struct {
+	user     string
+	password string
+}
+
+var passwdParams = []lexer.Param{
+	{Name: "-user", Help: "usuario, por defecto el de la sesion"},
+	{Name: "-pass", Required: true, MaxLen: 10, Help: "contrasena nueva"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "passwd",
+		Description: "Cambia la contrasena de un usuario",
+		Params:      passwdParams,
+		Handler:     Handle(ParsePasswd),
+	})
+}
+
+func ParsePasswd(args lexer.Args) (string, error) {
+	cmd := &PASSWD{user: args["-user"], password: args["-pass"]}
+
+	err := CommandPasswd(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PASSWD: contraseña de %s cambiada exitosamente", cmd.user), nil
+}
+
+// Cambia la contraseña del usuario logeado, o la de cualquiera si es root
+func CommandPasswd(passwd *PASSWD) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	if passwd.user == "" {
+		passwd.user = stores.LogedUser
+	}
+	if passwd.user != stores.LogedUser && stores.LogedUser != "root" {
+		return errors.New("solo el usuario root puede cambiar la contraseña de otro usuario")
+	}
+
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	row := findUserRow(passwd.user, contentMatrix)
+	if row == nil {
+		return errors.New("el nombre de usuario no existe")
+	}
+	return setUserPassword(sb, partition, diskPath, contentMatrix, row, passwd.password)
+}
+
+func findUserRow(userName string, matrix [][]string) []string {
+	for _, row := range matrix {
+		if len(row) < 5 || row[1] != "U" || row[0] == "0" {
+			continue
+		}
+		if row[3] == userName {
+			return row
+		}
+	}
+	return nil
+}
+
+// Guarda el hash de la contraseña en la fila del usuario y reescribe users.txt
+func setUserPassword(sb *structures.SuperBlock, partition *structures.PARTITION, diskPath string, matrix [][]string, row []string, password string) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	row[4] = hash
+	err = OverrideUserstxt(sb, diskPath, reformUserstxt(matrix))
+	if err != nil {
+		return err
+	}
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+	return addPasswdJournal(sb, partition, diskPath, row[3])
+}
+
+// En el journal solo va el nombre del usuario, ni la contraseña ni su hash.
+// Recovery deja al usuario bloqueado hasta que root le asigne otra.
+func addPasswdJournal(sb *structures.SuperBlock, partition *structures.PARTITION, diskPath, userName string) error {
+	if !sb.IsExt3() {
+		return nil
+	}
+	journalDirectory := &structures.Journal{
+		J_next: -1,
+		J_content: structures.Information{
+			I_operation: [10]byte{'p', 'a', 's', 's', 'w', 'd'},
+			I_path:      [74]byte{},
+			I_content:   [64]byte{},
+			I_date:      float32(time.Now().Unix()),
+		},
+	}
+	copy(journalDirectory.J_content.I_path[:], userName)
+	return sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+}
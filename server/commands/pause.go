@@ -1,2 +1,39 @@
 package commands
 
+import (
+	"bufio"
+	"fmt"
+	"server/config"
+	"server/lexer"
+)
+
+// Entrada de la consola interactiva, pause y fdisk -delete leen de aqui. Es nil
+// cuando los comandos vienen de un script, de -c o de una tuberia.
+var Terminal *bufio.Scanner
+
+func init() {
+	Register(&Command{
+		Name:        "pause",
+		Description: "Espera a que se presione ENTER",
+		Handler:     Handle(ParsePause),
+		Interactive: true,
+	})
+}
+
+func ParsePause(args lexer.Args) (string, error) {
+	if Terminal == nil {
+		return "PAUSE", nil
+	}
+	for {
+		if !config.Current.JSONOutput() {
+			fmt.Print("Apache ENTER para continuar: ")
+		}
+		if !Terminal.Scan() {
+			break
+		}
+		if Terminal.Text() == "" {
+			break
+		}
+	}
+	return "PAUSE", nil
+}
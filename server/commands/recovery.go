@@ -0,0 +1,331 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"strings"
+)
+
+type RECOVERY struct {
+	id string
+}
+
+var recoveryParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "recovery",
+		Description: "Recupera una particion EXT3 a partir de su journal",
+		Params:      recoveryParams,
+		Handler:     Handle(ParseRecovery),
+	})
+}
+
+func ParseRecovery(args lexer.Args) (string, error) {
+	cmd := &RECOVERY{id: args["-id"]}
+
+	count, err := CommandRecovery(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("RECOVERY: particion %s recuperada exitosamente (%d entradas del journal)", cmd.id, count), nil
+}
+
+// Operacion del journal ya armada: las entradas de mkfile y edit que ocupan
+// varias posiciones se juntan en una sola
+type recoveryStep struct {
+	entry     int
+	operation string
+	path      string
+	content   string
+}
+
+// Reconstruye el sistema de archivos desde cero y vuelve a aplicar cada entrada
+// del journal en orden. Las operaciones se repiten sin volver a escribir el journal.
+func CommandRecovery(recovery *RECOVERY) (int, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(recovery.id)
+	if err != nil {
+		return 0, err
+	}
+	if !sb.IsExt3() {
+		return 0, errors.New("la particion no es EXT3, no tiene journaling")
+	}
+	journals, err := sb.GetJournals(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})))
+	if err != nil {
+		return 0, err
+	}
+	// Se revisa todo el journal antes de borrar nada
+	steps, err := parseJournal(journals)
+	if err != nil {
+		return 0, err
+	}
+
+	n := sb.S_inodes_count + sb.S_free_inodes_count
+	err = zeroPartitionArea(diskPath, int64(sb.S_bm_inode_start), int64(partition.Part_start+partition.Part_size))
+	if err != nil {
+		return 0, err
+	}
+	sb.S_inodes_count = 0
+	sb.S_blocks_count = 0
+	sb.S_free_inodes_count = n
+	sb.S_free_blocks_count = 3 * n
+	sb.S_first_ino = sb.S_inode_start
+	sb.S_first_blo = sb.S_block_start
+	err = replaySteps(sb, diskPath, steps)
+	// Aunque falle una entrada, el superbloque queda de acuerdo con los bitmaps
+	serializeErr := sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return 0, err
+	}
+	if serializeErr != nil {
+		return 0, serializeErr
+	}
+	return len(journals), nil
+}
+
+func parseJournal(journals []structures.Journal) ([]recoveryStep, error) {
+	steps := make([]recoveryStep, 0, len(journals))
+	for i := 0; i < len(journals); i++ {
+		step := recoveryStep{
+			entry:     i + 1,
+			operation: journals[i].GetOperation(),
+			path:      journals[i].GetPath(),
+			content:   journals[i].GetContent(),
+		}
+		var err error
+		switch step.operation {
+		case "":
+			continue
+		case "mkfile":
+			// mkfile guarda el contenido en varias entradas seguidas de 64 bytes
+			for i+1 < len(journals) && journals[i+1].GetOperation() == "mkfile" && journals[i+1].GetPath() == step.path {
+				i++
+				step.content += journals[i].GetContent()
+			}
+		case "edit":
+			var size int
+			size, err = strconv.Atoi(step.content)
+			if err != nil {
+				break
+			}
+			step.content = ""
+			for len(step.content) < size && i+1 < len(journals) && journals[i+1].GetOperation() == "edit" {
+				i++
+				step.content += journals[i].GetContent()
+			}
+			if len(step.content) < size {
+				err = errors.New("el contenido esta incompleto")
+			}
+		case "mkusr":
+			if fields := strings.Split(step.content, "/"); len(fields) != 2 && len(fields) != 3 {
+				err = errors.New("entrada de mkusr invalida")
+			}
+		case "chgrp":
+			if len(strings.Split(step.content, "/")) != 3 {
+				err = errors.New("entrada de chgrp invalida")
+			}
+		case "login":
+			if len(strings.Split(step.content, "/")) < 2 {
+				err = errors.New("entrada de login invalida")
+			}
+		case "mkdir", "mkgrp", "rmgrp", "rmusr", "chmod", "chown", "copy", "move", "rename", "remove", "passwd", "logout":
+		default:
+			err = errors.New("operacion desconocida")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("el journal no se puede recuperar, entrada %d (%s %s): %w", step.entry, step.operation, step.path, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func replaySteps(sb *structures.SuperBlock, diskPath string, steps []recoveryStep) error {
+	err := sb.CreateBitMaps(diskPath)
+	if err != nil {
+		return err
+	}
+	err = sb.CreateUsersFile(diskPath, 0)
+	if err != nil {
+		return err
+	}
+
+	// Las entradas se aplican con el usuario que tenia la sesion en ese momento
+	userID, groupID, extraGroupIDs := utils.LogedUserID, utils.LogedUserGroupID, utils.LogedUserExtraGroupIDs
+	defer func() {
+		utils.LogedUserID, utils.LogedUserGroupID, utils.LogedUserExtraGroupIDs = userID, groupID, extraGroupIDs
+	}()
+	utils.LogedUserID, utils.LogedUserGroupID, utils.LogedUserExtraGroupIDs = 1, 1, nil
+
+	for _, step := range steps {
+		path, content := step.path, step.content
+		switch step.operation {
+		case "mkdir":
+			if path == "/" {
+				continue
+			}
+			parentDirs, destDir := utils.GetParentDirectories(path)
+			err = sb.CreateFolder(diskPath, parentDirs, destDir, true)
+		case "mkfile":
+			if path == "/users.txt" {
+				continue
+			}
+			err = replayMkfile(sb, diskPath, path, content)
+		case "edit":
+			err = applyEdit(sb, diskPath, path, content)
+		case "mkgrp", "rmgrp", "mkusr", "rmusr", "chgrp":
+			err = replayUsersJournal(sb, diskPath, step.operation, content)
+		case "chmod", "chown":
+			fields := strings.Split(content, "/")
+			recursive := len(fields) > 1 && fields[1] == "r"
+			if step.operation == "chmod" {
+				err = applyChmod(sb, diskPath, path, fields[0], recursive)
+			} else {
+				err = applyChown(sb, diskPath, path, fields[0], recursive)
+			}
+		case "copy":
+			err = applyCopy(sb, diskPath, path, content)
+		case "move":
+			err = applyMove(sb, diskPath, path, content)
+		case "rename":
+			err = applyRename(sb, diskPath, path, content)
+		case "remove":
+			err = applyRemove(sb, diskPath, path)
+			if errors.Is(err, errPartialRemove) {
+				err = nil
+			}
+		case "passwd":
+			err = replayPasswd(sb, diskPath, path)
+		case "login":
+			err = replayLogin(sb, diskPath, content)
+		case "logout":
+			utils.LogedUserID, utils.LogedUserGroupID, utils.LogedUserExtraGroupIDs = 1, 1, nil
+		}
+		if err != nil {
+			return fmt.Errorf("error al recuperar la entrada %d (%s %s): %w", step.entry, step.operation, path, err)
+		}
+	}
+	return unlockRoot(sb, diskPath)
+}
+
+func replayMkfile(sb *structures.SuperBlock, diskPath, filePath, content string) error {
+	position := strings.LastIndex(filePath, "/")
+	if position > 0 {
+		parentDirs, destDir := utils.GetParentDirectories(filePath[:position])
+		err := sb.CreateFolder(diskPath, parentDirs, destDir, true)
+		if err != nil {
+			return err
+		}
+	}
+	parentDirs, destDir := utils.GetParentDirectories(filePath)
+	return sb.CreateFile(diskPath, 0, parentDirs, destDir, content, int32(len(content)), false)
+}
+
+func getUsersTxt(sb *structures.SuperBlock, diskPath string) (string, error) {
+	parentDirs, destDir := utils.GetParentDirectories("/users.txt")
+	return sb.ContentFromFile(diskPath, 0, parentDirs, destDir)
+}
+
+func replayUsersJournal(sb *structures.SuperBlock, diskPath, operation, content string) error {
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	switch operation {
+	case "mkgrp":
+		contentUsersTxt += fmt.Sprintf("%d,G,%s\n", getNeoNumber("G", contentMatrix), content)
+	case "rmgrp":
+		if !removeGroup(content, contentMatrix) {
+			return errors.New("no existe el nombre del grupo a eliminar")
+		}
+		contentUsersTxt = reformUserstxt(contentMatrix)
+	case "mkusr":
+		// Antes se guardaba user/pass/grp; ahora solo user/grp y el usuario
+		// queda bloqueado hasta que root le asigne una contraseña
+		fields := strings.Split(content, "/")
+		password := utils.LockedPassword
+		switch len(fields) {
+		case 2:
+		case 3:
+			password, err = utils.HashPassword(fields[1])
+			if err != nil {
+				return err
+			}
+			fields = []string{fields[0], fields[2]}
+		default:
+			return errors.New("entrada de mkusr invalida")
+		}
+		contentUsersTxt += fmt.Sprintf("%d,U,%s,%s,%s\n", getNeoNumber("U", contentMatrix), fields[1], fields[0], password)
+	case "rmusr":
+		if !removeUser(content, contentMatrix) {
+			return errors.New("el nombre de usuario no existe")
+		}
+		contentUsersTxt = reformUserstxt(contentMatrix)
+	case "chgrp":
+		fields := strings.Split(content, "/")
+		if len(fields) != 3 {
+			return errors.New("entrada de chgrp invalida")
+		}
+		err = applyChgrp(fields[0], fields[1], fields[2], contentMatrix)
+		if err != nil {
+			return err
+		}
+		contentUsersTxt = reformUserstxt(contentMatrix)
+	}
+	return OverrideUserstxt(sb, diskPath, contentUsersTxt)
+}
+
+func replayLogin(sb *structures.SuperBlock, diskPath, content string) error {
+	fields := strings.Split(content, "/")
+	if len(fields) < 2 {
+		return errors.New("entrada de login invalida")
+	}
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	return setUpIDs(fields[1], getContentMatrixUsers(contentUsersTxt))
+}
+
+// El journal no guarda la contraseña nueva, el usuario queda bloqueado
+func replayPasswd(sb *structures.SuperBlock, diskPath, userName string) error {
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	row := findUserRow(userName, contentMatrix)
+	if row == nil {
+		return errors.New("el nombre de usuario no existe")
+	}
+	row[4] = utils.LockedPassword
+	return OverrideUserstxt(sb, diskPath, reformUserstxt(contentMatrix))
+}
+
+// Si root quedo bloqueado (por un passwd o por el users.txt que guarda tune) se
+// le devuelve la contraseña de mkfs, si no nadie podria volver a entrar
+func unlockRoot(sb *structures.SuperBlock, diskPath string) error {
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	row := findUserRow("root", contentMatrix)
+	if row == nil || row[4] != utils.LockedPassword {
+		return nil
+	}
+	row[4], err = utils.HashPassword(utils.DefaultRootPassword)
+	if err != nil {
+		return err
+	}
+	return OverrideUserstxt(sb, diskPath, reformUserstxt(contentMatrix))
+}
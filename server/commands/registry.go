@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"server/lexer"
+	"slices"
+)
+
+// Recibe los parametros ya validados y con sus valores por defecto, devuelve
+// un string o un result.Payload
+type Handler func(args lexer.Args) (any, error)
+
+type Command struct {
+	Name        string
+	Description string
+	Params      []lexer.Param
+	Handler     Handler
+	Interactive bool //Espera a alguien en la terminal, no se puede usar en el servidor
+}
+
+var registry = make(map[string]*Command)
+
+// Se activa al iniciar el servidor HTTP, ahi nadie puede responder a un
+// comando interactivo y se quedaria esperando con las peticiones bloqueadas
+var ServerMode bool
+
+// Cada comando se registra en el init de su archivo
+func Register(cmd *Command) {
+	if _, exists := registry[cmd.Name]; exists {
+		panic("comando registrado dos veces: " + cmd.Name)
+	}
+	registry[cmd.Name] = cmd
+}
+
+func Lookup(name string) (*Command, bool) {
+	cmd, exists := registry[name]
+	return cmd, exists
+}
+
+// Comandos registrados en orden alfabetico
+func Registered() []*Command {
+	commands := make([]*Command, 0, len(registry))
+	for _, name := range slices.Sorted(maps.Keys(registry)) {
+		commands = append(commands, registry[name])
+	}
+	return commands
+}
+
+// Valida los parametros contra los declarados por el comando y lo ejecuta
+func Run(name string, tokens []lexer.Token) (any, error) {
+	cmd, exists := registry[name]
+	if !exists {
+		return nil, unknownCommand(name)
+	}
+	if cmd.Interactive && ServerMode {
+		return nil, fmt.Errorf("%s espera a alguien en la terminal, no se puede usar en el servidor", name)
+	}
+	err := lexer.Validate(tokens, cmd.Params)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.Handler(lexer.NewArgs(lexer.WithDefaults(tokens, cmd.Params), cmd.Params))
+}
+
+// Adapta un Parse* que devuelve un tipo concreto a Handler
+func Handle[T any](parse func(args lexer.Args) (T, error)) Handler {
+	return func(args lexer.Args) (any, error) {
+		return parse(args)
+	}
+}
+
+// Comando registrado mas parecido a name, o vacio si ninguno se parece lo
+// suficiente (a lo mucho un tercio del nombre mas una letra de diferencia)
+func Suggest(name string) string {
+	best, bestDistance := "", len(name)/3+2
+	for _, candidate := range slices.Sorted(maps.Keys(registry)) {
+		distance := editDistance(name, candidate)
+		if distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func unknownCommand(name string) error {
+	if suggestion := Suggest(name); suggestion != "" {
+		return fmt.Errorf("comando desconocido: %s, quiso decir %s?", name, suggestion)
+	}
+	return fmt.Errorf("comando desconocido: %s", name)
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"time"
+)
+
+type REMOVE struct {
+	path string
+}
+
+var removeParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta a eliminar"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "remove",
+		Description: "Elimina un archivo o carpeta",
+		Params:      removeParams,
+		Handler:     Handle(ParseRemove),
+	})
+}
+
+func ParseRemove(args lexer.Args) (string, error) {
+	cmd := &REMOVE{path: args["-path"]}
+
+	err := CommandRemove(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("REMOVE: %s eliminado exitosamente", cmd.path), nil
+}
+
+func CommandRemove(remove *REMOVE) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(remove.path, "")
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	removeErr := applyRemove(sb, diskPath, remove.path)
+	// Aunque la eliminacion quede a medias ya se liberaron inodos y bloques
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+	// A medias tambien se registra: recovery la repite con el mismo usuario y
+	// vuelve a quedar igual
+	if removeErr != nil && !errors.Is(removeErr, errPartialRemove) {
+		return removeErr
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'r', 'e', 'm', 'o', 'v', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], remove.path)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return removeErr
+}
+
+var errPartialRemove = errors.New("hay elementos sin permiso de escritura")
+
+// Elimina el archivo o la carpeta. Si algun hijo no tiene permiso de escritura
+// se conserva junto con las carpetas que lo contienen.
+func applyRemove(sb *structures.SuperBlock, diskPath, path string) error {
+	inode, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	if inodeIndex == 0 {
+		return errors.New("no se puede eliminar la carpeta raiz")
+	}
+	parentInode, parentIndex, err := reports.UbicarInodo(sb, getParentPath(path), diskPath)
+	if err != nil {
+		return err
+	}
+	outcome, err := parentInode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("no tiene permisos de escritura en la carpeta que lo contiene")
+	}
+
+	if inode.I_type[0] == '0' {
+		outcome, err = sb.RemoveInodo0(diskPath, inodeIndex)
+	} else {
+		outcome, err = sb.RemoveInodo1(diskPath, inodeIndex)
+	}
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return fmt.Errorf("no se pudo eliminar %s por completo, %w", path, errPartialRemove)
+	}
+
+	err = sb.ReleaseInode(diskPath, inodeIndex)
+	if err != nil {
+		return err
+	}
+	_, name := utils.GetParentDirectories(path)
+	return sb.RemoveEntryFromFolder(diskPath, parentIndex, name)
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/reports"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strings"
+	"time"
+)
+
+type RENAME struct {
+	path string
+	name string
+}
+
+var renameParams = []lexer.Param{
+	{Name: "-path", Required: true, Help: "ruta actual"},
+	{Name: "-name", Required: true, Help: "nombre nuevo"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "rename",
+		Description: "Cambia el nombre de un archivo o carpeta",
+		Params:      renameParams,
+		Handler:     Handle(ParseRename),
+	})
+}
+
+func ParseRename(args lexer.Args) (string, error) {
+	cmd := &RENAME{path: args["-path"], name: args["-name"]}
+
+	err := CommandRename(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("RENAME: %s renombrado a %s exitosamente", cmd.path, cmd.name), nil
+}
+
+func CommandRename(rename *RENAME) error {
+	if stores.LogedIdPartition == "" {
+		return errors.New("no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		err = structures.CheckJournalFields(rename.path, rename.name)
+		if err != nil {
+			return err
+		}
+		err = sb.CheckJournalSpace(diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
+	err = applyRename(sb, diskPath, rename.path, rename.name)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'r', 'e', 'n', 'a', 'm', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], rename.path)
+		copy(journalDirectory.J_content.I_content[:], rename.name)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyRename(sb *structures.SuperBlock, diskPath, path, name string) error {
+	if len(name) > 12 {
+		return errors.New("el nombre no puede tener mas de 12 caracteres")
+	}
+	if strings.Contains(name, "/") || name == "." || name == ".." || name == "-" {
+		return errors.New("el nombre no es valido")
+	}
+	_, inodeIndex, err := reports.UbicarInodo(sb, path, diskPath)
+	if err != nil {
+		return err
+	}
+	if inodeIndex == 0 {
+		return errors.New("no se puede renombrar la carpeta raiz")
+	}
+	// El nombre se guarda en el bloque de la carpeta padre, ahi es donde se escribe
+	parent, parentIndex, err := reports.UbicarInodo(sb, getParentPath(path), diskPath)
+	if err != nil {
+		return err
+	}
+	outcome, err := parent.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("no tiene permisos de escritura sobre la carpeta que lo contiene")
+	}
+	existing, err := sb.FindEntryInFolder(diskPath, parentIndex, name)
+	if err != nil {
+		return err
+	}
+	// Se permite cambiar solo mayusculas y minusculas del mismo nombre
+	if existing != -1 && existing != inodeIndex {
+		return fmt.Errorf("ya existe %s en la carpeta", name)
+	}
+	_, oldName := utils.GetParentDirectories(path)
+	return sb.RenameEntryInFolder(diskPath, parentIndex, oldName, name)
+}
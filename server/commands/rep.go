@@ -1,10 +1,11 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
+	"path/filepath"
 	ext3 "server/Ext3Info"
+	"server/config"
+	"server/lexer"
 	"server/reports"
 	"server/stores"
 	"strings"
@@ -17,83 +18,31 @@ type REP struct {
 	ruta string
 }
 
-func ParseRep(tokens []string) (string, error) {
-	cmd := &REP{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-ruta="[^"]+"|-ruta=[^\s]+|-path="[^"]+"|-path=[^\s]+|-name=[a-zA-Z_]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var repParams = []lexer.Param{
+	{Name: "-name", Required: true, Values: []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "tree", "sb", "file", "ls", "journaling"}, Help: "reporte"},
+	{Name: "-path", Required: true, Help: "ruta de salida del reporte"},
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+	{Name: "-ruta", Help: "archivo o carpeta para los reportes file y ls"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(&Command{
+		Name:        "rep",
+		Description: "Genera un reporte",
+		Params:      repParams,
+		Handler:     Handle(ParseRep),
+	})
+}
 
-		switch key {
-		case "-ruta":
-			if value == "" {
-				return "", errors.New("el ruta no puede estar vacio")
-			}
-			cmd.ruta = value
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el name no puede estar vacio")
-			}
-			value = strings.ToLower(value)
-			switch value {
-			case "mbr":
-				cmd.name = "mbr"
-			case "disk":
-				cmd.name = "disk"
-			case "inode":
-				cmd.name = "inode"
-			case "block":
-				cmd.name = "block"
-			case "bm_inode":
-				cmd.name = "bm_inode"
-			case "bm_block":
-				cmd.name = "bm_block"
-			case "tree":
-				cmd.name = "tree"
-			case "sb":
-				cmd.name = "sb"
-			case "file":
-				cmd.name = "file"
-			case "ls":
-				cmd.name = "ls"
-			case "journaling":
-				cmd.name = "journaling"
-			default:
-				return "", fmt.Errorf("valor del nombre invalido: %s", value)
-			}
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
+func ParseRep(args lexer.Args) (string, error) {
+	cmd := &REP{
+		name: strings.ToLower(args["-name"]),
+		path: args["-path"],
+		id:   args["-id"],
+		ruta: args["-ruta"],
 	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -path")
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parametros requeridos: -name")
+	if !filepath.IsAbs(cmd.path) && config.Current.ReportDir != "" {
+		cmd.path = filepath.Join(config.Current.ReportDir, cmd.path)
 	}
 
 	err := commandRep(cmd)
@@ -109,10 +58,17 @@ func commandRep(rep *REP) error {
 	if err != nil {
 		return err
 	}
+	// mbr y disk no leen el sistema de archivos
+	if rep.name != "mbr" && rep.name != "disk" {
+		err = mountedSb.CheckVersion()
+		if err != nil {
+			return err
+		}
+	}
 
 	switch rep.name {
 	case "mbr":
-		err = reports.ReportMBR(mountedMbr, rep.path, rep.id)
+		err = reports.ReportMBR(mountedMbr, rep.path, rep.id, mountedDiskPath)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"server/config"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strings"
+)
+
+type RESIZEDISK struct {
+	path        string
+	driveLetter string
+	add         int
+	unit        string
+}
+
+var resizediskParams = []lexer.Param{
+	{Name: "-path", Help: "ruta del disco"},
+	{Name: "-driveletter", Pattern: `[A-Za-z]`, Help: "letra del disco"},
+	{Name: "-add", Type: lexer.Int, Required: true, Pattern: `-?[1-9]\d*`, Help: "espacio a agregar o quitar (negativo)"},
+	{Name: "-unit", Values: []string{"B", "K", "M"}, Help: "unidad (por defecto la de la configuracion)"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "resizedisk",
+		Description: "Agranda o recorta un disco",
+		Params:      resizediskParams,
+		Handler:     Handle(ParseResizedisk),
+	})
+}
+
+func ParseResizedisk(args lexer.Args) (string, error) {
+	cmd := &RESIZEDISK{
+		path:        args["-path"],
+		driveLetter: args["-driveletter"],
+		add:         args.Int("-add"),
+		unit:        strings.ToUpper(args["-unit"]),
+	}
+
+	path, err := stores.ResolveDiskPath(cmd.path, cmd.driveLetter)
+	if err != nil {
+		return "", err
+	}
+	cmd.path = path
+	if cmd.unit == "" {
+		cmd.unit = config.Current.DiskUnit
+	}
+
+	size, err := CommandResizedisk(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("RESIZEDISK: %s redimensionado a %d bytes exitosamente", cmd.path, size), nil
+}
+
+// Agranda el archivo del disco (sin escribir los bytes nuevos) o lo recorta si
+// ninguna particion queda fuera del nuevo final
+func CommandResizedisk(resize *RESIZEDISK) (int32, error) {
+	amount, err := utils.ConvertToBytes(resize.add, resize.unit)
+	if err != nil {
+		return 0, err
+	}
+	if !fileExists(resize.path) {
+		return 0, errors.New("el archivo no existe en el path solicitado")
+	}
+
+	mbr := &structures.MBR{}
+	err = mbr.DeserializeMBR(resize.path)
+	if err != nil {
+		return 0, err
+	}
+
+	neoSize := int64(mbr.Mbr_size) + int64(amount)
+	if neoSize <= int64(binary.Size(mbr)) {
+		return 0, errors.New("el disco quedaria sin espacio ni para el MBR")
+	}
+	if neoSize > int64(^uint32(0)>>1) {
+		return 0, errors.New("el disco no puede superar los 2 GB que caben en Mbr_size")
+	}
+	// Las logicas y sus EBR estan dentro de la extendida, basta revisar el MBR
+	for _, partition := range mbr.Mbr_partitions {
+		if partition.Part_start == -1 || partition.Part_size <= 0 {
+			continue
+		}
+		if int64(partition.Part_start)+int64(partition.Part_size) > neoSize {
+			name := strings.Trim(string(partition.Part_name[:]), "\x00 ")
+			return 0, fmt.Errorf("no se puede reducir el disco porque la particion %s quedaria fuera", name)
+		}
+	}
+
+	err = os.Truncate(resize.path, neoSize)
+	if err != nil {
+		return 0, fmt.Errorf("error al redimensionar el disco: %v", err)
+	}
+	mbr.Mbr_size = int32(neoSize)
+	err = mbr.SerializeMBR(resize.path)
+	if err != nil {
+		return 0, err
+	}
+	return mbr.Mbr_size, nil
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+)
+
+type RESIZEFS struct {
+	id string
+}
+
+var resizefsParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "resizefs",
+		Description: "Ajusta el sistema de archivos al tamano de su particion",
+		Params:      resizefsParams,
+		Handler:     Handle(ParseResizefs),
+	})
+}
+
+func ParseResizefs(args lexer.Args) (string, error) {
+	cmd := &RESIZEFS{id: args["-id"]}
+
+	oldN, neoN, err := CommandResizefs(cmd)
+	if err != nil {
+		return "", err
+	}
+	if oldN == neoN {
+		return fmt.Sprintf("RESIZEFS: %s ya ocupa toda la particion (%d inodos)", cmd.id, neoN), nil
+	}
+	return fmt.Sprintf("RESIZEFS: %s redimensionado de %d a %d inodos exitosamente", cmd.id, oldN, neoN), nil
+}
+
+// Ajusta el sistema de archivos al tamano actual de la particion, por ejemplo
+// despues de un fdisk -add
+func CommandResizefs(resize *RESIZEFS) (int32, int32, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(resize.id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if sb.S_magic != 0xEF53 {
+		return 0, 0, errors.New("la particion no tiene un sistema de archivos")
+	}
+
+	fs := "2fs"
+	if sb.IsExt3() {
+		fs = "3fs"
+	}
+	oldN := sb.S_bm_block_start - sb.S_bm_inode_start
+	n := calculateN(partition, fs)
+	if n == oldN {
+		return oldN, n, nil
+	}
+	if n < 2 {
+		return 0, 0, errors.New("la particion es muy pequena para el sistema de archivos")
+	}
+
+	journalStart, bmInodeStart, bmBlockStart, inodeStart, blockStart := calculateStartPositions(partition, fs, n)
+	err = sb.Relocate(diskPath, journalStart, structures.Layout{
+		N:            n,
+		BmInodeStart: bmInodeStart,
+		BmBlockStart: bmBlockStart,
+		InodeStart:   inodeStart,
+		BlockStart:   blockStart,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return 0, 0, err
+	}
+	return oldN, n, nil
+}
@@ -1,55 +1,48 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"server/lexer"
 	"server/stores"
-	"strings"
 )
 
 type RMDISK struct {
-	path string
+	path        string
+	driveLetter string
 }
 
-func ParseRmdisk(tokens []string) (string, error) {
-	cmd := &RMDISK{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-driveletter=[A-Za-z]`)
-	matches := re.FindAllString(args, -1)
+var rmdiskParams = []lexer.Param{
+	{Name: "-path", Help: "ruta del disco"},
+	{Name: "-driveletter", Pattern: `[A-Za-z]`, Help: "letra del disco"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func init() {
+	Register(&Command{
+		Name:        "rmdisk",
+		Description: "Elimina un disco",
+		Params:      rmdiskParams,
+		Handler:     Handle(ParseRmdisk),
+	})
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func ParseRmdisk(args lexer.Args) (string, error) {
+	cmd := &RMDISK{path: args["-path"], driveLetter: args["-driveletter"]}
 
-		switch key {
-		case "-driveletter":
-			if value == "'" {
-				return "", errors.New("el driveletter no puede venir vacio")
-			}
-			cmd.path = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -driveletter")
+	path, err := stores.ResolveDiskPath(cmd.path, cmd.driveLetter)
+	if err != nil {
+		return "", err
 	}
-	cmd.path = stores.GetPathDisk(cmd.path)
-	err := commandRmdisk(cmd)
+	cmd.path = path
+	err = commandRmdisk(cmd)
 	if err != nil {
 		return "", err
 	}
 	stores.DeleteMountedPartitions(cmd.path)
+	err = stores.SaveState()
+	if err != nil {
+		return "", err
+	}
 	return fmt.Sprintf("RMDISK: %s eliminado exitosamente", cmd.path), nil
 
 }
@@ -4,7 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	"strings"
@@ -15,38 +15,22 @@ type RMGRP struct {
 	name string
 }
 
-func ParseRmgrp(tokens []string) (string, error) {
-	cmd := &RMGRP{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var rmgrpParams = []lexer.Param{
+	{Name: "-name", Required: true, Help: "nombre del grupo"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(&Command{
+		Name:        "rmgrp",
+		Description: "Elimina un grupo",
+		Params:      rmgrpParams,
+		Handler:     Handle(ParseRmgrp),
+	})
+}
 
-		switch key {
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede venir vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func ParseRmgrp(args lexer.Args) (string, error) {
+	cmd := &RMGRP{name: args["-name"]}
 
-	if cmd.name == "" {
-		return "", errors.New("parametro obligatorio: -name")
-	}
 	err := CommandRmgrp(cmd)
 	if err != nil {
 		return "", err
@@ -77,6 +61,12 @@ func CommandRmgrp(rmgrp *RMGRP) error {
 	if err != nil {
 		return err
 	}
+	if partitionSuperblock.IsExt3() {
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
 	err = OverrideUserstxt(partitionSuperblock, partitionPath, contentUsersTxt)
 	if err != nil {
 		return err
@@ -111,7 +101,7 @@ func reformUserstxt(matrix [][]string) string {
 	for _, row := range matrix {
 		onlyRows = append(onlyRows, strings.Join(row, ","))
 	}
-	fullContent := strings.Join(onlyRows, "\n")
+	fullContent := strings.Join(onlyRows, "\n") + "\n"
 	return fullContent
 }
 
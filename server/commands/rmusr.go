@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
-	"strings"
 	"time"
 )
 
@@ -15,41 +14,21 @@ type RMUSR struct {
 	user string
 }
 
-func ParseRmusr(tokens []string) (string, error) {
-	cmd := &RMUSR{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-user="[^"]+"|-user=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var rmusrParams = []lexer.Param{
+	{Name: "-user", Required: true, MaxLen: 10, Help: "nombre del usuario"},
+}
 
-		switch key {
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el user de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.user = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(&Command{
+		Name:        "rmusr",
+		Description: "Elimina un usuario",
+		Params:      rmusrParams,
+		Handler:     Handle(ParseRmusr),
+	})
+}
 
-	if cmd.user == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
-	}
+func ParseRmusr(args lexer.Args) (string, error) {
+	cmd := &RMUSR{user: args["-user"]}
 
 	err := CommandoRmusr(cmd)
 	if err != nil {
@@ -81,6 +60,12 @@ func CommandoRmusr(rmusr *RMUSR) error {
 	if err != nil {
 		return err
 	}
+	if partitionSuperblock.IsExt3() {
+		err = partitionSuperblock.CheckJournalSpace(partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))), 1)
+		if err != nil {
+			return err
+		}
+	}
 	err = OverrideUserstxt(partitionSuperblock, partitionPath, contentUsersTxt)
 	if err != nil {
 		return err
@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"time"
+)
+
+type TUNE struct {
+	id      string
+	journal bool
+}
+
+var tuneParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+	{Name: "-journal", Type: lexer.Flag, Required: true, Help: "agrega el journal"},
+}
+
+func init() {
+	Register(&Command{
+		Name:        "tune",
+		Description: "Convierte una particion EXT2 a EXT3",
+		Params:      tuneParams,
+		Handler:     Handle(ParseTune),
+	})
+}
+
+func ParseTune(args lexer.Args) (string, error) {
+	cmd := &TUNE{id: args["-id"], journal: args.Has("-journal")}
+
+	entries, err := CommandTune(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("TUNE: %s convertida a EXT3 exitosamente (%d entradas en el journal)", cmd.id, entries), nil
+}
+
+/*
+Convierte una particion EXT2 a EXT3 sin perder datos: mueve bitmaps, inodos y
+bloques para dejar el journal despues del superbloque y lo llena con entradas
+que reconstruyen el arbol actual, asi recovery funciona desde ese momento.
+*/
+func CommandTune(tune *TUNE) (int, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(tune.id)
+	if err != nil {
+		return 0, err
+	}
+	if sb.S_magic != 0xEF53 {
+		return 0, errors.New("la particion no tiene un sistema de archivos")
+	}
+	if sb.IsExt3() {
+		return 0, errors.New("la particion ya es EXT3")
+	}
+
+	// users.txt va al journal sin las contraseñas, con recovery los usuarios
+	// quedan bloqueados
+	contentUsersTxt, err := getUsersTxt(sb, diskPath)
+	if err != nil {
+		return 0, err
+	}
+	journals, err := getTreeJournals(sb, diskPath, lockPasswords(contentUsersTxt))
+	if err != nil {
+		return 0, err
+	}
+	// Una cuarta parte del journal queda libre para las operaciones que vengan
+	// despues, si el arbol no deja ese espacio es mejor no convertir
+	n := calculateN(partition, "3fs")
+	if int32(len(journals)) > n-n/4 {
+		return 0, fmt.Errorf("el journal de %d entradas no alcanza para registrar el arbol actual (%d entradas) y dejar al menos %d libres", n, len(journals), n/4)
+	}
+
+	journalStart, bmInodeStart, bmBlockStart, inodeStart, blockStart := calculateStartPositions(partition, "3fs", n)
+	layout := structures.Layout{
+		N:            n,
+		BmInodeStart: bmInodeStart,
+		BmBlockStart: bmBlockStart,
+		InodeStart:   inodeStart,
+		BlockStart:   blockStart,
+	}
+	// Relocate revisa que todo quepa antes de mover algo
+	err = sb.Relocate(diskPath, journalStart, layout)
+	if err != nil {
+		return 0, err
+	}
+	// Las entradas quedan seguidas, igual que las que agrega AddJournal
+	journalSize := int32(binary.Size(structures.Journal{}))
+	for i := range journals {
+		offset := journalStart + int32(i)*journalSize
+		journals[i].J_next = offset + journalSize
+		if i == len(journals)-1 {
+			journals[i].J_next = -1
+		}
+		err = journals[i].Serialize(diskPath, int64(offset))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	sb.S_filesystem_type = 3
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return 0, err
+	}
+	return len(journals), nil
+}
+
+/*
+Entradas que al aplicarlas con recovery dejan el arbol como esta ahora: mkdir y
+mkfile en preorden, users.txt como un edit y al final chown y chmod de lo que
+no tenga el propietario o los permisos con los que recovery lo crearia.
+*/
+func getTreeJournals(sb *structures.SuperBlock, diskPath, contentUsersTxt string) ([]structures.Journal, error) {
+	userNames := make(map[int32]string)
+	for _, row := range getContentMatrixUsers(contentUsersTxt) {
+		if len(row) < 4 || row[1] != "U" || row[0] == "0" {
+			continue
+		}
+		id, err := strconv.Atoi(row[0])
+		if err == nil {
+			userNames[int32(id)] = row[3]
+		}
+	}
+
+	journals := []structures.Journal{newTreeJournal("mkdir", "/", "")}
+	ownership := make([]structures.Journal, 0)
+	err := sb.WalkTree(diskPath, func(path string, inodeIndex int32, inode *structures.Inode) error {
+		if len(path) > len(structures.Information{}.I_path) {
+			return fmt.Errorf("la ruta %s es muy larga para guardarse en el journal", path)
+		}
+		defaultPerm := "664"
+		if path == "/" || path == "/users.txt" {
+			defaultPerm = "777"
+		}
+		if name, exists := userNames[inode.I_uid]; exists && inode.I_uid != 1 {
+			ownership = append(ownership, newTreeJournal("chown", path, name))
+		}
+		if perm := string(inode.I_perm[:]); perm != defaultPerm {
+			ownership = append(ownership, newTreeJournal("chmod", path, perm))
+		}
+
+		if path == "/" {
+			return nil
+		}
+		if inode.I_type[0] == '0' {
+			journals = append(journals, newTreeJournal("mkdir", path, ""))
+			return nil
+		}
+		if path == "/users.txt" {
+			// recovery crea el users.txt por defecto, se reemplaza con un edit
+			journals = append(journals, newTreeJournal("edit", path, strconv.Itoa(len(contentUsersTxt))))
+			for _, chunk := range utils.SplitStringIntoChunks(contentUsersTxt) {
+				journals = append(journals, newTreeJournal("edit", path, chunk))
+			}
+			return nil
+		}
+		content, err := sb.ReadFileContent(diskPath, inodeIndex)
+		if err != nil {
+			return err
+		}
+		if content == "" {
+			journals = append(journals, newTreeJournal("mkfile", path, ""))
+			return nil
+		}
+		for _, chunk := range utils.SplitStringIntoChunks(content) {
+			journals = append(journals, newTreeJournal("mkfile", path, chunk))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(journals, ownership...), nil
+}
+
+// users.txt con la contraseña de cada usuario cambiada por LockedPassword
+func lockPasswords(contentUsersTxt string) string {
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	for _, row := range contentMatrix {
+		if len(row) >= 5 && row[1] == "U" {
+			row[4] = utils.LockedPassword
+		}
+	}
+	return reformUserstxt(contentMatrix)
+}
+
+func newTreeJournal(operation, path, content string) structures.Journal {
+	journal := structures.Journal{
+		J_next: -1,
+		J_content: structures.Information{
+			I_operation: [10]byte{},
+			I_path:      [74]byte{},
+			I_content:   [64]byte{},
+			I_date:      float32(time.Now().Unix()),
+		},
+	}
+	copy(journal.J_content.I_operation[:], operation)
+	copy(journal.J_content.I_path[:], path)
+	copy(journal.J_content.I_content[:], content)
+	return journal
+}
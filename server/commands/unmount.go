@@ -3,11 +3,9 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
-	"strings"
 )
 
 type UNMOUNT struct {
@@ -17,36 +15,30 @@ type UNMOUNT struct {
 // Validar si esta montada
 // Cambiar el valor del estado a 0
 
-func ParseUnmount(tokens []string) (string, error) {
-	cmd := &UNMOUNT{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+`)
-	matches := re.FindAllString(args, -1)
+var unmountParams = []lexer.Param{
+	{Name: "-id", Required: true, Pattern: `[a-zA-Z0-9]+`, Help: "id de la particion montada"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
+func init() {
+	Register(&Command{
+		Name:        "unmount",
+		Description: "Desmonta una particion",
+		Params:      unmountParams,
+		Handler:     Handle(ParseUnmount),
+	})
+}
+
+func ParseUnmount(args lexer.Args) (string, error) {
+	cmd := &UNMOUNT{id: args["-id"]}
 
-		}
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
-	}
 	err := CommandUnmount(cmd)
 	if err != nil {
 		return "", err
 	}
+	err = stores.SaveState()
+	if err != nil {
+		return "", err
+	}
 	return fmt.Sprintf("UNMOUNT: %s desmontado exitosamente", cmd.id), nil
 
 }
@@ -63,7 +55,12 @@ func CommandUnmount(unmount *UNMOUNT) error {
 	}
 	partition, index, err := mbr.GetPartitionByID(unmount.id)
 	if err != nil {
-		return err
+		err = unmountLogicalPartition(mbr, diskPath, unmount.id)
+		if err != nil {
+			return err
+		}
+		delete(stores.MountedPartitions, unmount.id)
+		return nil
 	}
 	if partition.Part_status[0] == '0' {
 		return errors.New("no se puede desmontar una particion no montada")
@@ -74,7 +71,18 @@ func CommandUnmount(unmount *UNMOUNT) error {
 	if err != nil {
 		return err
 	}
-	utils.PathToPartitionCount[diskPath] -= 1
 	delete(stores.MountedPartitions, unmount.id)
 	return nil
 }
+
+func unmountLogicalPartition(mbr *structures.MBR, diskPath, id string) error {
+	if !mbr.IsThereExtendedPartition() {
+		return errors.New("partición no encontrada")
+	}
+	ebr, err := mbr.GetLogicalPartitionByID(diskPath, id)
+	if err != nil {
+		return err
+	}
+	ebr.Ebr_status[0] = '0'
+	return ebr.Serialize(diskPath, int64(ebr.Ebr_start))
+}
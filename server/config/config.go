@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Ajustes de ejecucion. Se cargan en este orden y cada fuente pisa a la anterior:
+// valores por defecto, archivo JSON, variables de entorno MIA_* y banderas.
+type Config struct {
+	DiskDir       string `json:"disk_dir"`       //Carpeta de los discos creados con -driveletter, relativa al directorio actual si no es absoluta
+	ReportDir     string `json:"report_dir"`     //Raiz para los reportes con path relativo
+	IDSuffix      string `json:"id_suffix"`      //Sufijo de los IDs de particion (carnet)
+	DiskUnit      string `json:"disk_unit"`      //Unidad por defecto de mkdisk
+	PartitionUnit string `json:"partition_unit"` //Unidad por defecto de fdisk
+	DiskFit       string `json:"disk_fit"`       //Ajuste por defecto de mkdisk
+	PartitionFit  string `json:"partition_fit"`  //Ajuste por defecto de fdisk
+	Color         bool   `json:"color"`
+	Output        string `json:"output"` //text o json (una linea JSON por comando)
+}
+
+const DefaultFile string = "config.json"
+
+var Current = Default()
+
+var flagValues struct {
+	file          string
+	diskDir       string
+	reportDir     string
+	idSuffix      string
+	diskUnit      string
+	partitionUnit string
+	diskFit       string
+	partitionFit  string
+	color         bool
+	output        string
+	json          bool
+}
+
+func Default() Config {
+	return Config{
+		DiskDir:       "disks",
+		ReportDir:     "",
+		IDSuffix:      "05",
+		DiskUnit:      "K",
+		PartitionUnit: "K",
+		DiskFit:       "FF",
+		PartitionFit:  "WF",
+		Color:         true,
+		Output:        "text",
+	}
+}
+
+// Registra las banderas de configuracion, se aplican al llamar Load
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&flagValues.file, "config", "", "archivo de configuracion JSON (por defecto config.json o $MIA_CONFIG)")
+	fs.StringVar(&flagValues.diskDir, "disk-dir", "", "carpeta de los discos")
+	fs.StringVar(&flagValues.reportDir, "report-dir", "", "raiz de los reportes con path relativo")
+	fs.StringVar(&flagValues.idSuffix, "id-suffix", "", "sufijo de los IDs de particion")
+	fs.StringVar(&flagValues.diskUnit, "disk-unit", "", "unidad por defecto de mkdisk (K o M)")
+	fs.StringVar(&flagValues.partitionUnit, "partition-unit", "", "unidad por defecto de fdisk (B, K o M)")
+	fs.StringVar(&flagValues.diskFit, "disk-fit", "", "ajuste por defecto de mkdisk (BF, FF o WF)")
+	fs.StringVar(&flagValues.partitionFit, "partition-fit", "", "ajuste por defecto de fdisk (BF, FF o WF)")
+	fs.BoolVar(&flagValues.color, "color", true, "salida con colores ANSI")
+	fs.StringVar(&flagValues.output, "output", "", "formato de salida (text o json)")
+	fs.BoolVar(&flagValues.json, "json", false, "atajo de -output=json")
+}
+
+// Carga la configuracion en Current. fs puede ser nil si no se usan banderas.
+func Load(fs *flag.FlagSet) error {
+	cfg := Default()
+
+	file := flagValues.file
+	if file == "" {
+		file = os.Getenv("MIA_CONFIG")
+	}
+	err := cfg.loadFile(file)
+	if err != nil {
+		return err
+	}
+	err = cfg.loadEnv()
+	if err != nil {
+		return err
+	}
+	if fs != nil {
+		cfg.loadFlags(fs)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return err
+	}
+	Current = cfg
+	return nil
+}
+
+// Sin path se intenta config.json del directorio actual, y si no existe se
+// siguen usando los valores por defecto
+func (cfg *Config) loadFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("no se pudo leer la configuracion %s: %v", path, err)
+	}
+	err = json.Unmarshal(content, cfg)
+	if err != nil {
+		return fmt.Errorf("el archivo de configuracion %s no es valido: %v", path, err)
+	}
+	return nil
+}
+
+func (cfg *Config) loadEnv() error {
+	fields := map[string]*string{
+		"MIA_DISK_DIR":       &cfg.DiskDir,
+		"MIA_REPORT_DIR":     &cfg.ReportDir,
+		"MIA_ID_SUFFIX":      &cfg.IDSuffix,
+		"MIA_DISK_UNIT":      &cfg.DiskUnit,
+		"MIA_PARTITION_UNIT": &cfg.PartitionUnit,
+		"MIA_DISK_FIT":       &cfg.DiskFit,
+		"MIA_PARTITION_FIT":  &cfg.PartitionFit,
+		"MIA_OUTPUT":         &cfg.Output,
+	}
+	for name, field := range fields {
+		if value, exists := os.LookupEnv(name); exists {
+			*field = value
+		}
+	}
+	if value, exists := os.LookupEnv("MIA_COLOR"); exists {
+		color, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("MIA_COLOR debe ser true o false: %s", value)
+		}
+		cfg.Color = color
+	}
+	return nil
+}
+
+// Solo se aplican las banderas que vinieron en la linea de comandos
+func (cfg *Config) loadFlags(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "disk-dir":
+			cfg.DiskDir = flagValues.diskDir
+		case "report-dir":
+			cfg.ReportDir = flagValues.reportDir
+		case "id-suffix":
+			cfg.IDSuffix = flagValues.idSuffix
+		case "disk-unit":
+			cfg.DiskUnit = flagValues.diskUnit
+		case "partition-unit":
+			cfg.PartitionUnit = flagValues.partitionUnit
+		case "disk-fit":
+			cfg.DiskFit = flagValues.diskFit
+		case "partition-fit":
+			cfg.PartitionFit = flagValues.partitionFit
+		case "color":
+			cfg.Color = flagValues.color
+		case "output":
+			cfg.Output = flagValues.output
+		case "json":
+			if flagValues.json {
+				cfg.Output = "json"
+			}
+		}
+	})
+}
+
+func (cfg *Config) Validate() error {
+	cfg.DiskUnit = strings.ToUpper(cfg.DiskUnit)
+	cfg.PartitionUnit = strings.ToUpper(cfg.PartitionUnit)
+	cfg.DiskFit = strings.ToUpper(cfg.DiskFit)
+	cfg.PartitionFit = strings.ToUpper(cfg.PartitionFit)
+	cfg.Output = strings.ToLower(cfg.Output)
+
+	if cfg.DiskDir == "" {
+		return errors.New("la carpeta de discos no puede estar vacia")
+	}
+	// El ID (letra, correlativo y sufijo) se guarda en los 4 bytes de Part_id,
+	// con un sufijo de 2 digitos solo caben 9 particiones montadas por disco
+	if cfg.IDSuffix == "" || len(cfg.IDSuffix) > 2 {
+		return errors.New("el sufijo de los IDs debe tener 1 o 2 digitos")
+	}
+	for _, r := range cfg.IDSuffix {
+		if r < '0' || r > '9' {
+			return errors.New("el sufijo de los IDs debe ser numerico")
+		}
+	}
+	if cfg.DiskUnit != "K" && cfg.DiskUnit != "M" {
+		return errors.New("la unidad por defecto de mkdisk debe ser K o M")
+	}
+	if cfg.PartitionUnit != "B" && cfg.PartitionUnit != "K" && cfg.PartitionUnit != "M" {
+		return errors.New("la unidad por defecto de fdisk debe ser B, K o M")
+	}
+	if !isFit(cfg.DiskFit) || !isFit(cfg.PartitionFit) {
+		return errors.New("el ajuste por defecto debe ser BF, FF o WF")
+	}
+	if cfg.Output != "text" && cfg.Output != "json" {
+		return errors.New("el formato de salida debe ser text o json")
+	}
+	return nil
+}
+
+func (cfg *Config) JSONOutput() bool {
+	return cfg.Output == "json"
+}
+
+func isFit(fit string) bool {
+	return fit == "BF" || fit == "FF" || fit == "WF"
+}
@@ -2,11 +2,12 @@ package console
 
 import (
 	"fmt"
+	"server/result"
 	"strings"
 )
 
-// Códigos de color ANSI
-const (
+// Códigos de color ANSI, quedan vacios si se desactivan los colores
+var (
 	Reset     = "\033[0m"
 	Bold      = "\033[1m"
 	Dim       = "\033[2m"
@@ -46,6 +47,22 @@ const (
 	BgWhite   = "\033[47m"
 )
 
+// Desactiva los colores ANSI, por ejemplo al redirigir la salida a un archivo
+func SetColor(enabled bool) {
+	if enabled {
+		return
+	}
+	codes := []*string{
+		&Reset, &Bold, &Dim, &Underline, &Blink, &Reverse, &Hidden,
+		&Black, &Red, &Green, &Yellow, &Blue, &Magenta, &Cyan, &White,
+		&BrightBlack, &BrightRed, &BrightGreen, &BrightYellow, &BrightBlue, &BrightMagenta, &BrightCyan, &BrightWhite,
+		&BgBlack, &BgRed, &BgGreen, &BgYellow, &BgBlue, &BgMagenta, &BgCyan, &BgWhite,
+	}
+	for _, code := range codes {
+		*code = ""
+	}
+}
+
 func PrintHeader(title string) {
 	width := 80
 	fmt.Printf("%s%s\n", Bold+BrightCyan, strings.Repeat("═", width))
@@ -92,6 +109,43 @@ func PrintCommand(command string) {
 		Bold, BrightMagenta, BrightWhite, command, Reset, Reset)
 }
 
+// Muestra el resultado de un comando (y los de su script si fue un execute). El
+// contenido de cat, find y mounted se imprime completo, lo demas queda para el
+// resumen final.
+func PrintResult(res result.Result) {
+	lines := res.Lines()
+	for i, line := range lines {
+		switch line.Data.(type) {
+		case *result.CatContents, *result.FindHits, *result.MountedList, *result.Help:
+			if line.OK() {
+				fmt.Println(line.Message)
+			}
+		}
+		// Errores de los comandos de un script, ya vienen con su linea. El
+		// ultimo es el propio resultado y se muestra abajo.
+		if !line.OK() && i < len(lines)-1 {
+			PrintError(line.Message)
+		}
+	}
+	if !res.OK() {
+		PrintError(res.Message)
+		return
+	}
+	PrintSuccess("Comando ejecutado correctamente")
+}
+
+// Muestra el mensaje de cada comando (y los de su script si fue un execute).
+// Fuera de la consola interactiva no hay resumen final, ahi se usa esta.
+func PrintLines(res result.Result) {
+	for _, line := range res.Lines() {
+		if line.OK() {
+			PrintSuccess(line.Message)
+		} else {
+			PrintError(line.Message)
+		}
+	}
+}
+
 func PrintSeparator() {
 	fmt.Printf("%s%s%s%s\n", Dim, BrightCyan, strings.Repeat("─", 80), Reset)
 }
@@ -2,9 +2,13 @@ module server
 
 go 1.23.6
 
+require (
+	github.com/gofiber/fiber/v2 v2.52.6
+	golang.org/x/crypto v0.31.0
+)
+
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
-	github.com/gofiber/fiber/v2 v2.52.6 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
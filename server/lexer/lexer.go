@@ -0,0 +1,285 @@
+package lexer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Parametro de una linea de comando. Key va en minusculas y con el guion
+// (-path), Value ya viene sin comillas ni escapes y Pos es la columna (desde 1)
+// donde empieza el parametro en la linea.
+type Token struct {
+	Key      string
+	Value    string
+	HasValue bool
+	Pos      int
+}
+
+type ParamType int
+
+const (
+	String ParamType = iota
+	Int
+	Flag //No lleva valor (-r)
+)
+
+// Parametro que acepta un comando. Con Numbered la clave lleva un numero al
+// final (-file1, -file2) y con Positional es una palabra suelta sin guion
+// (help mkdir). Values son los valores permitidos sin importar mayusculas y
+// Pattern, si no esta vacio, la expresion que debe cumplir el valor completo.
+// El valor no puede venir vacio (-path="") salvo con AllowEmpty, y con MaxLen
+// no puede pasar de esa cantidad de caracteres.
+type Param struct {
+	Name       string
+	Type       ParamType
+	Required   bool
+	Default    string
+	Values     []string
+	Numbered   bool
+	Positional bool
+	Pattern    string
+	AllowEmpty bool
+	MaxLen     int
+	Help       string
+}
+
+// Valores de una linea ya validada, por nombre del parametro (-path). Las
+// banderas quedan con valor vacio, las palabras sueltas con el nombre de su
+// parametro Positional y los numerados con su clave completa (-file1).
+type Args map[string]string
+
+/*
+Separa una linea en el nombre del comando y sus parametros. Los valores pueden ir
+entre comillas dobles o simples para incluir espacios (-path="/home/mis docs"),
+dentro de ellas \" y \\ se escriben literal, y un # al inicio de una palabra
+comenta el resto de la linea. Las palabras sin guion quedan con Key vacio, solo
+las aceptan los comandos con un parametro Positional.
+*/
+func Tokenize(input string) (string, []Token, error) {
+	words, err := splitWords(input)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(words) == 0 {
+		return "", nil, nil
+	}
+
+	name := strings.ToLower(words[0].text)
+	tokens := make([]Token, 0, len(words)-1)
+	for _, word := range words[1:] {
+		token := Token{Key: strings.ToLower(word.text), Pos: word.pos}
+		if word.equals != -1 {
+			token.Key = strings.ToLower(word.text[:word.equals])
+			token.Value = word.text[word.equals+1:]
+			token.HasValue = true
+		}
+		if !strings.HasPrefix(token.Key, "-") && !token.HasValue {
+			token = Token{Value: word.text, HasValue: true, Pos: word.pos}
+		} else if !strings.HasPrefix(token.Key, "-") || token.Key == "-" {
+			return "", nil, fmt.Errorf("parametro invalido en la columna %d: %s", word.pos, word.text)
+		}
+		tokens = append(tokens, token)
+	}
+	return name, tokens, nil
+}
+
+type word struct {
+	text   string
+	pos    int
+	equals int //Indice del primer = fuera de comillas, -1 si no hay
+}
+
+func splitWords(input string) ([]word, error) {
+	runes := []rune(input)
+	words := make([]word, 0)
+	for i := 0; i < len(runes); {
+		if isSpace(runes[i]) {
+			i++
+			continue
+		}
+		if runes[i] == '#' {
+			break
+		}
+
+		current := word{pos: i + 1, equals: -1}
+		var text strings.Builder
+		var quote rune
+		quoteStart := 0
+		for ; i < len(runes) && (quote != 0 || !isSpace(runes[i])); i++ {
+			r := runes[i]
+			switch {
+			case quote != 0 && r == '\\' && i+1 < len(runes) && (runes[i+1] == quote || runes[i+1] == '\\'):
+				i++
+				text.WriteRune(runes[i])
+			case quote != 0 && r == quote:
+				quote = 0
+			case quote == 0 && (r == '"' || r == '\''):
+				quote = r
+				quoteStart = i + 1
+			case quote == 0 && r == '=' && current.equals == -1:
+				current.equals = text.Len()
+				text.WriteRune(r)
+			default:
+				text.WriteRune(r)
+			}
+		}
+		if quote != 0 {
+			return nil, fmt.Errorf("comillas sin cerrar en la columna %d", quoteStart)
+		}
+		current.text = text.String()
+		words = append(words, current)
+	}
+	return words, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// Revisa que cada parametro este declarado, tenga la forma correcta y que
+// vengan todos los obligatorios
+func Validate(tokens []Token, params []Param) error {
+	for _, token := range tokens {
+		param := findParam(token.Key, params)
+		if param == nil && token.Key == "" {
+			return fmt.Errorf("parametro invalido en la columna %d: %s", token.Pos, token.Value)
+		}
+		if param == nil {
+			return fmt.Errorf("parametro desconocido en la columna %d: %s", token.Pos, token.Key)
+		}
+		if param.Type == Flag {
+			if token.HasValue {
+				return fmt.Errorf("el parametro %s no lleva valor (columna %d)", token.Key, token.Pos)
+			}
+			continue
+		}
+		if !token.HasValue {
+			return fmt.Errorf("el parametro %s necesita un valor (columna %d)", token.Key, token.Pos)
+		}
+		if token.Value == "" {
+			if param.AllowEmpty {
+				continue
+			}
+			return fmt.Errorf("el parametro %s no puede estar vacio (columna %d)", token.Key, token.Pos)
+		}
+		if param.MaxLen > 0 && len(token.Value) > param.MaxLen {
+			return fmt.Errorf("el parametro %s no puede tener mas de %d caracteres (columna %d)", token.Key, param.MaxLen, token.Pos)
+		}
+		if param.Type == Int {
+			if _, err := strconv.Atoi(token.Value); err != nil {
+				return fmt.Errorf("el parametro %s debe ser un numero entero (columna %d): %s", token.Key, token.Pos, token.Value)
+			}
+		}
+		if len(param.Values) > 0 && !slices.ContainsFunc(param.Values, func(allowed string) bool {
+			return strings.EqualFold(allowed, token.Value)
+		}) {
+			return fmt.Errorf("valor invalido para %s en la columna %d: %s (se permite %s)", token.Key, token.Pos, token.Value, strings.Join(param.Values, ", "))
+		}
+		if param.Pattern != "" && !regexp.MustCompile(`^(?:`+param.Pattern+`)$`).MatchString(token.Value) {
+			return fmt.Errorf("valor invalido para %s en la columna %d: %s", token.Key, token.Pos, token.Value)
+		}
+	}
+
+	for i := range params {
+		if params[i].Required && !hasParam(tokens, &params[i]) {
+			return fmt.Errorf("faltan parametros requeridos: %s", params[i].Usage())
+		}
+	}
+	return nil
+}
+
+// Agrega los parametros con valor por defecto que no vienen en la linea
+func WithDefaults(tokens []Token, params []Param) []Token {
+	for i := range params {
+		if params[i].Default != "" && !hasParam(tokens, &params[i]) {
+			tokens = append(tokens, Token{Key: params[i].Name, Value: params[i].Default, HasValue: true})
+		}
+	}
+	return tokens
+}
+
+// Junta los parametros de la linea, ya validados y con sus valores por defecto,
+// en un mapa por nombre. Si un parametro se repite queda el ultimo valor.
+func NewArgs(tokens []Token, params []Param) Args {
+	args := make(Args, len(tokens))
+	for _, token := range tokens {
+		key := token.Key
+		if param := findParam(key, params); param != nil && param.Positional {
+			key = param.Name
+		}
+		args[key] = token.Value
+	}
+	return args
+}
+
+func (a Args) Has(name string) bool {
+	_, exists := a[name]
+	return exists
+}
+
+// Valor de un parametro Int, 0 si no viene (Validate ya reviso que sea entero)
+func (a Args) Int(name string) int {
+	value, _ := strconv.Atoi(a[name])
+	return value
+}
+
+// Valores de un parametro Numbered por su numero, -file2 queda en 2
+func (a Args) Numbered(name string) map[int]string {
+	values := make(map[int]string)
+	for key, value := range a {
+		if !strings.HasPrefix(key, name) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimPrefix(key, name))
+		if err == nil {
+			values[number] = value
+		}
+	}
+	return values
+}
+
+// Forma en que se escribe el parametro: -path, -r, -fileN o <comando>
+func (p *Param) Usage() string {
+	switch {
+	case p.Positional:
+		return "<" + p.Name + ">"
+	case p.Numbered:
+		return p.Name + "N"
+	}
+	return p.Name
+}
+
+func hasParam(tokens []Token, param *Param) bool {
+	for _, token := range tokens {
+		if findParam(token.Key, []Param{*param}) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func findParam(key string, params []Param) *Param {
+	for i, param := range params {
+		if param.Positional {
+			if key == "" {
+				return &params[i]
+			}
+			continue
+		}
+		if param.Numbered {
+			// La numeracion empieza en 1 (-file0 no es valido)
+			number := strings.TrimPrefix(key, param.Name)
+			if number != key && strings.Trim(number, "0123456789") == "" && strings.TrimLeft(number, "0") != "" {
+				return &params[i]
+			}
+			continue
+		}
+		if key == param.Name {
+			return &params[i]
+		}
+	}
+	return nil
+}
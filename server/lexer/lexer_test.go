@@ -0,0 +1,184 @@
+package lexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		command string
+		tokens  []Token
+	}{
+		{
+			name:    "comillas con espacios",
+			input:   `mkdir -path="/home/mis docs"`,
+			command: "mkdir",
+			tokens:  []Token{{Key: "-path", Value: "/home/mis docs", HasValue: true, Pos: 7}},
+		},
+		{
+			name:    "comillas simples",
+			input:   `mkdir -path='/home/mis docs' -r`,
+			command: "mkdir",
+			tokens: []Token{
+				{Key: "-path", Value: "/home/mis docs", HasValue: true, Pos: 7},
+				{Key: "-r", Pos: 30},
+			},
+		},
+		{
+			name:    "comilla escapada",
+			input:   `set -name=A -value="di \"hola\""`,
+			command: "set",
+			tokens: []Token{
+				{Key: "-name", Value: "A", HasValue: true, Pos: 5},
+				{Key: "-value", Value: `di "hola"`, HasValue: true, Pos: 13},
+			},
+		},
+		{
+			name:    "diagonal invertida escapada",
+			input:   `set -name=A -value="c:\\temp\\"`,
+			command: "set",
+			tokens: []Token{
+				{Key: "-name", Value: "A", HasValue: true, Pos: 5},
+				{Key: "-value", Value: `c:\temp\`, HasValue: true, Pos: 13},
+			},
+		},
+		{
+			name:    "numeral dentro de comillas",
+			input:   `mkfile -path="/a#b.txt" # comentario`,
+			command: "mkfile",
+			tokens:  []Token{{Key: "-path", Value: "/a#b.txt", HasValue: true, Pos: 8}},
+		},
+		{
+			name:    "claves con mayusculas",
+			input:   `MkDisk -Size=5 -UNIT=M -Path=/tmp/A.dsk`,
+			command: "mkdisk",
+			tokens: []Token{
+				{Key: "-size", Value: "5", HasValue: true, Pos: 8},
+				{Key: "-unit", Value: "M", HasValue: true, Pos: 16},
+				{Key: "-path", Value: "/tmp/A.dsk", HasValue: true, Pos: 24},
+			},
+		},
+		{
+			name:    "palabra suelta",
+			input:   `help mkdir`,
+			command: "help",
+			tokens:  []Token{{Value: "mkdir", HasValue: true, Pos: 6}},
+		},
+		{
+			name:    "linea comentada",
+			input:   `   # mkdisk -size=5`,
+			command: "",
+			tokens:  nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, tokens, err := Tokenize(test.input)
+			if err != nil {
+				t.Fatalf("Tokenize(%q) devolvio error: %v", test.input, err)
+			}
+			if command != test.command {
+				t.Errorf("comando = %q, se esperaba %q", command, test.command)
+			}
+			if len(tokens) == 0 && len(test.tokens) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(tokens, test.tokens) {
+				t.Errorf("tokens = %+v, se esperaba %+v", tokens, test.tokens)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"comillas sin cerrar", `mkdir -path="/home`, "comillas sin cerrar en la columna 13"},
+		{"clave sin guion", `mkdir path=/home`, "parametro invalido en la columna 7: path=/home"},
+		{"guion solo", `mkdir - -r`, "parametro invalido en la columna 7: -"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := Tokenize(test.input)
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Tokenize(%q) = %v, se esperaba %q", test.input, err, test.err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	params := []Param{
+		{Name: "-path", Required: true},
+		{Name: "-size", Type: Int, Pattern: `\d+`},
+		{Name: "-unit", Values: []string{"K", "M"}},
+		{Name: "-user", MaxLen: 10},
+		{Name: "-r", Type: Flag},
+		{Name: "-file", Numbered: true},
+	}
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"valido", `cmd -path=/a -size=5 -unit=m -r -file1=/b`, ""},
+		{"parametro desconocido", `cmd -path=/a -color=rojo`, "parametro desconocido en la columna 14: -color"},
+		{"desconocido con mayusculas", `cmd -PATH=/a -Color`, "parametro desconocido en la columna 14: -color"},
+		{"numerado desde cero", `cmd -path=/a -file0=/b`, "parametro desconocido en la columna 14: -file0"},
+		{"palabra suelta", `cmd -path=/a extra`, "parametro invalido en la columna 14: extra"},
+		{"falta obligatorio", `cmd -size=5`, "faltan parametros requeridos: -path"},
+		{"valor vacio", `cmd -path=""`, "el parametro -path no puede estar vacio (columna 5)"},
+		{"bandera con valor", `cmd -path=/a -r=1`, "el parametro -r no lleva valor (columna 14)"},
+		{"falta valor", `cmd -path`, "el parametro -path necesita un valor (columna 5)"},
+		{"entero invalido", `cmd -path=/a -size=dos`, "el parametro -size debe ser un numero entero (columna 14): dos"},
+		{"valor no permitido", `cmd -path=/a -unit=G`, "valor invalido para -unit en la columna 14: G (se permite K, M)"},
+		{"muy largo", `cmd -path=/a -user=abcdefghijk`, "el parametro -user no puede tener mas de 10 caracteres (columna 14)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, tokens, err := Tokenize(test.input)
+			if err != nil {
+				t.Fatalf("Tokenize(%q) devolvio error: %v", test.input, err)
+			}
+			err = Validate(tokens, params)
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("Validate(%q) devolvio error: %v", test.input, err)
+			case test.err != "" && (err == nil || err.Error() != test.err):
+				t.Errorf("Validate(%q) = %v, se esperaba %q", test.input, err, test.err)
+			}
+		})
+	}
+}
+
+func TestNewArgs(t *testing.T) {
+	params := []Param{
+		{Name: "-path", Required: true},
+		{Name: "-fs", Default: "2fs"},
+		{Name: "-r", Type: Flag},
+		{Name: "-file", Numbered: true},
+		{Name: "comando", Positional: true},
+	}
+	_, tokens, err := Tokenize(`cmd mkdir -path=/a -r -file2=/c -file1=/b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := NewArgs(WithDefaults(tokens, params), params)
+	want := Args{"comando": "mkdir", "-path": "/a", "-fs": "2fs", "-r": "", "-file1": "/b", "-file2": "/c"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("NewArgs = %v, se esperaba %v", args, want)
+	}
+	if !args.Has("-r") || args.Has("-x") {
+		t.Errorf("Has no distingue las banderas presentes")
+	}
+	files := args.Numbered("-file")
+	if !reflect.DeepEqual(files, map[int]string{1: "/b", 2: "/c"}) {
+		t.Errorf("Numbered = %v", files)
+	}
+}
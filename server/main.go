@@ -2,26 +2,137 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"runtime"
 	"server/analyzer"
+	"server/api"
+	"server/commands"
+	"server/config"
 	"server/console"
+	"server/result"
+	"server/stores"
 	"strings"
 )
 
 var outcome string
 
+// Lineas con error, incluidas las de los scripts de execute. Si hay alguna el
+// proceso termina con codigo 1.
+var failed int
+
+// Solo la consola interactiva junta los mensajes en un resumen al salir
+var summary bool
+
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
+	serve := flag.String("serve", "", "inicia el servidor HTTP en la direccion indicada (por ejemplo :3000, sin host escucha en 127.0.0.1) en lugar de la consola")
+	token := flag.String("token", os.Getenv("MIA_TOKEN"), "token que el servidor exige en Authorization: Bearer <token> (por defecto $MIA_TOKEN)")
+	command := flag.String("c", "", "ejecuta un solo comando y termina")
+	stopOnError := flag.Bool("stop-on-error", false, "deja de leer comandos despues del primero que falle")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Uso: %s [banderas] [script]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Sin -c ni script lee los comandos de la entrada estandar; si no es una terminal no muestra bienvenida ni resumen.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	err := config.Load(flag.CommandLine)
+	if err != nil {
+		console.PrintError(fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+	console.SetColor(config.Current.Color)
+
+	if *serve != "" {
+		err = runServer(*serve, *token)
+		if err != nil {
+			console.PrintError(fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch {
+	case *command != "":
+		loadState()
+		printResult(analyzer.Analyzer(*command))
+	case flag.NArg() == 1:
+		content, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			console.PrintError(fmt.Sprintf("no se pudo abrir el script: %v", err))
+			os.Exit(1)
+		}
+		loadState()
+		script := analyzer.RunScript(flag.Arg(0), strings.Split(string(content), "\n"), *stopOnError)
+		printResult(result.New("execute", script, nil))
+	case !isTerminal(os.Stdin):
+		loadState()
+		readCommands(os.Stdin, false, *stopOnError)
+	default:
+		runConsole(*stopOnError)
+	}
 
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runConsole(stopOnError bool) {
 	// Limpiar consola y mostrar bienvenida estética
+	if !config.Current.JSONOutput() {
+		clearConsole()
+		console.PrintWelcome()
+	}
+
+	summary = true
+	loadState()
+	readCommands(os.Stdin, true, stopOnError)
+
+	if config.Current.JSONOutput() {
+		return
+	}
+
+	// Mostrar resumen final con estilo
 	clearConsole()
-	console.PrintWelcome()
+	console.PrintFinalSeparator()
+
+	if outcome != "" {
+		fmt.Println(outcome)
+	} else {
+		console.PrintInfo("No se ejecutaron comandos")
+	}
+
+	console.PrintGoodbye()
+}
+
+// Recupera las particiones montadas y la sesion de la ejecucion anterior
+func loadState() {
+	err := stores.LoadState()
+	if err != nil {
+		printResult(result.New("", nil, err))
+	}
+}
 
+// Ejecuta una linea a la vez hasta exit o el fin de la entrada. Solo en la
+// consola interactiva se muestra el prompt y el aviso de los comentarios.
+func readCommands(reader io.Reader, interactive bool, stopOnError bool) {
+	scanner := bufio.NewScanner(reader)
+	if interactive {
+		// pause lee de la misma entrada, con otro scanner se perderian lineas
+		commands.Terminal = scanner
+	}
 	for {
-		console.PrintPrompt()
+		if interactive && !config.Current.JSONOutput() {
+			console.PrintPrompt()
+		}
 
 		if !scanner.Scan() {
 			break
@@ -31,37 +142,95 @@ func main() {
 		if input == "exit" {
 			break
 		} else if strings.HasPrefix(input, "#") {
-			console.PrintInfo("Comentario ignorado")
+			if interactive && !config.Current.JSONOutput() {
+				console.PrintInfo("Comentario ignorado")
+			}
 			continue
 		} else if input == "" {
 			continue
 		}
 
 		// Mostrar comando que se va a ejecutar
-		console.PrintCommand(input)
+		if !config.Current.JSONOutput() {
+			console.PrintCommand(input)
+		}
 
-		msg, err := analyzer.Analyzer(input)
-		if err != nil {
-			console.PrintError(fmt.Sprintf("%v", err))
-			outcome += fmt.Sprintf("❌ Error: %v\n", err)
-		} else {
-			console.PrintSuccess("Comando ejecutado correctamente")
-			outcome += fmt.Sprintf("✅ %v\n", msg)
+		printResult(analyzer.Analyzer(input))
+		if stopOnError && failed > 0 {
+			break
 		}
-		console.PrintSeparator()
 	}
+}
 
-	// Mostrar resumen final con estilo
-	clearConsole()
-	console.PrintFinalSeparator()
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
 
-	if outcome != "" {
-		fmt.Println(outcome)
-	} else {
-		console.PrintInfo("No se ejecutaron comandos")
+// En modo json cada comando (y cada comando de un execute) es una linea JSON.
+// En modo texto la consola lo guarda para el resumen, los demas modos muestran
+// el mensaje de cada comando.
+func printResult(res result.Result) {
+	for _, line := range res.Lines() {
+		if !line.OK() {
+			failed++
+		}
+	}
+	if config.Current.JSONOutput() {
+		for _, line := range res.Lines() {
+			fmt.Println(line.JSON())
+		}
+		return
+	}
+	if !summary {
+		console.PrintLines(res)
+		console.PrintSeparator()
+		return
 	}
+	console.PrintResult(res)
+	for _, line := range res.Lines() {
+		if line.OK() {
+			outcome += fmt.Sprintf("✅ %v\n", line.Message)
+		} else {
+			outcome += fmt.Sprintf("❌ Error: %v\n", line.Message)
+		}
+	}
+	console.PrintSeparator()
+}
 
-	console.PrintGoodbye()
+func runServer(address, token string) error {
+	address, err := serverAddress(address, token)
+	if err != nil {
+		return err
+	}
+	err = stores.LoadState()
+	if err != nil {
+		return err
+	}
+	commands.ServerMode = true
+	console.PrintInfo(fmt.Sprintf("Servidor MIA escuchando en %s", address))
+	return api.NewServer(token).Listen(address)
+}
+
+// Sin host se escucha solo en 127.0.0.1. Fuera de la maquina local hace falta
+// un token, si no cualquiera en la red podria ejecutar comandos.
+func serverAddress(address, token string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("direccion invalida %s: %v", address, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ip := net.ParseIP(host)
+	loopback := host == "localhost" || (ip != nil && ip.IsLoopback())
+	if !loopback && token == "" {
+		return "", fmt.Errorf("para escuchar en %s hace falta -token o MIA_TOKEN", host)
+	}
+	return net.JoinHostPort(host, port), nil
 }
 
 func clearConsole() {
@@ -24,14 +24,18 @@ func ReportBlock(superBlock *structures.SuperBlock, diskPath, path string) error
         node [shape=plaintext]
 		rankdir=LR;
 		`
-	for i := int32(0); i < superBlock.S_inodes_count; i++ {
+	usedInodes, err := superBlock.GetUsedInodes(diskPath)
+	if err != nil {
+		return err
+	}
+	for index, i := range usedInodes {
 		inode := &structures.Inode{}
 		err := inode.Deserialize(diskPath, int64(superBlock.S_inode_start+(superBlock.S_inode_size*i)))
 		if err != nil {
 			return err
 		}
 		var content string
-		if i < superBlock.S_inodes_count-1 {
+		if index < len(usedInodes)-1 {
 			content, err = getStringBlock(inode, diskPath, superBlock, false)
 		} else {
 			content, err = getStringBlock(inode, diskPath, superBlock, true)
@@ -65,91 +69,19 @@ func ReportBlock(superBlock *structures.SuperBlock, diskPath, path string) error
 
 func getStringBlock(inode *structures.Inode, diskPath string, sb *structures.SuperBlock, isTheLast bool) (string, error) {
 	dotContent := ""
-	for i, blockIndex := range inode.I_block {
-
-		if blockIndex == -1 {
-			continue
-		}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return "", err
+	}
+	pointers, err := sb.GetInodePointerBlocks(diskPath, inode)
+	if err != nil {
+		return "", err
+	}
+	total := len(blocks) + len(pointers)
+	for i, blockIndex := range blocks {
 		fValue := getNumber()
 		// Aqui diferenciar si es de tipo 0 o 1 el Inodo
-		if i >= 14 {
-			pointerBlock := structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return "", err
-			}
-			dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Apuntador%d\n`, fValue, blockIndex)
-			for index, value := range pointerBlock.P_pointers {
-				if index%6 == 0 {
-					dotContent += "\n"
-				}
-				dotContent += fmt.Sprintf(" %d,", value)
-			}
-			dotContent += `"];
-			`
-			for iTe, neoIndexBlock := range pointerBlock.P_pointers {
-				if neoIndexBlock == -1 {
-					continue
-				}
-				neoFValue := getNumber()
-				if inode.I_type[0] == '0' {
-					block := &structures.FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoIndexBlock*sb.S_block_size)))
-					if err != nil {
-						return "", err
-					}
-					dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Carpeta%d\nb_name : b_inodo\n`, neoFValue, neoIndexBlock)
-					for _, value := range block.B_content {
-						tempString := string(value.B_name[:])
-						nameTemp := strings.TrimRight(tempString, "\x00")
-						dotContent += fmt.Sprintf(" %s : %d\\n", nameTemp, int32(value.B_inodo))
-					}
-					dotContent += `"];
-					`
-					for neoInodeIndex := 2; i < len(block.B_content); i++ {
-
-						content := block.B_content[neoInodeIndex]
-						if content.B_inodo == -1 {
-							continue
-						}
-						inode := &structures.Inode{}
-						err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(sb.S_inode_size*content.B_inodo)))
-						if err != nil {
-							return "", err
-						}
-						temp, err := getStringBlock(inode, diskPath, sb, false)
-						if err != nil {
-							return "", err
-						}
-						dotContent += temp
-					}
-					if !isTheLast {
-						if i < len(inode.I_block)-1 {
-							dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-						}
-					} else {
-						if i < len(inode.I_block)-1 && inode.I_block[i+1] != -1 {
-							dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-						}
-					}
-				} else if inode.I_type[0] == '1' {
-					if iTe == 0 {
-						dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
-					}
-					block := &structures.FileBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoIndexBlock*sb.S_block_size)))
-					if err != nil {
-						return "", err
-					}
-					contentBlock := string(block.B_content[:])
-					contentBlock = strings.TrimRight(contentBlock, "\x00")
-					splitContent := splitEqualParts(contentBlock)
-					dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque archivo %d\n%s\n%s\n%s\n%s"];
-					`, neoFValue, neoIndexBlock, splitContent[0], splitContent[1], splitContent[2], splitContent[3])
-					dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-				}
-			}
-		} else if inode.I_type[0] == '0' {
+		if inode.I_type[0] == '0' {
 			block := &structures.FolderBlock{}
 			err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 			if err != nil {
@@ -179,16 +111,30 @@ func getStringBlock(inode *structures.Inode, diskPath string, sb *structures.Sup
 					`, fValue, blockIndex, splitContent[0], splitContent[1], splitContent[2], splitContent[3])
 
 		}
-		if !isTheLast {
-			if i < len(inode.I_block)-1 {
-				dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
-			}
-		} else {
-			if i < len(inode.I_block)-1 && inode.I_block[i+1] != -1 {
-				dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
+		if !isTheLast || i < total-1 {
+			dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
+		}
+	}
+	// Los bloques de apuntadores (simple, doble y triple) van despues de los de datos
+	for i, pointerIndex := range pointers {
+		fValue := getNumber()
+		pointerBlock := structures.PointerBlock{}
+		err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(pointerIndex*sb.S_block_size)))
+		if err != nil {
+			return "", err
+		}
+		dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Apuntador%d\n`, fValue, pointerIndex)
+		for index, value := range pointerBlock.P_pointers {
+			if index%6 == 0 {
+				dotContent += "\n"
 			}
+			dotContent += fmt.Sprintf(" %d,", value)
+		}
+		dotContent += `"];
+			`
+		if !isTheLast || len(blocks)+i < total-1 {
+			dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
 		}
-
 	}
 	return dotContent, nil
 }
@@ -1,12 +1,14 @@
 package reports
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
 	"os/exec"
 	"server/stores"
 	"server/structures"
 	"server/utils"
+	"sort"
 )
 
 var contador int32 = 0
@@ -33,15 +35,28 @@ func ReportDisk(mbr *structures.MBR, idDisk string, path string, pathDisk string
 	`, getNumberNode(), "MBR")
 	percentageUsed := percentageMBR
 
+	// Las particiones se dibujan en el orden en que estan en el disco, con los
+	// huecos libres que hayan quedado entre ellas
+	partitions := make([]structures.PARTITION, 0, len(mbr.Mbr_partitions))
 	for _, partition := range mbr.Mbr_partitions {
-		var tipoParticion string
-		if partition.Part_type[0] == 'P' {
-			tipoParticion = "Primaria"
-		} else if partition.Part_type[0] == 'E' {
+		if partition.Part_type[0] == 'P' || partition.Part_type[0] == 'E' {
+			partitions = append(partitions, partition)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Part_start < partitions[j].Part_start })
+
+	cursor := int32(binary.Size(structures.MBR{}))
+	for _, partition := range partitions {
+		if partition.Part_start > cursor {
+			percentageFree := float64(partition.Part_start-cursor) / float64(tamanoTotalDisco) * 100
+			percentageUsed += percentageFree
+			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+	`, getNumberNode(), "Libre", percentageFree)
+		}
+		cursor = partition.Part_start + partition.Part_size
+		tipoParticion := "Primaria"
+		if partition.Part_type[0] == 'E' {
 			tipoParticion = "Extendida"
-		} else {
-			//Significa que no esta siendo utilizada esta particion
-			break
 		}
 		percentagePartition := (float64(partition.Part_size) / float64(tamanoTotalDisco)) * 100
 		percentageUsed += percentagePartition
@@ -51,8 +66,12 @@ func ReportDisk(mbr *structures.MBR, idDisk string, path string, pathDisk string
 			rankdir=LR
 			`, tipoParticion)
 
-			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%d%%"];
-			}`, getNumberNode(), "Libre", 100)
+			temp, err := getExtendedDOT(mbr, &partition, pathDisk)
+			if err != nil {
+				return err
+			}
+			dotContent += temp + `
+			}`
 		} else {
 			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
 			`, getNumberNode(), tipoParticion, percentagePartition)
@@ -88,3 +107,35 @@ func getNumberNode() int32 {
 	contador++
 	return contador
 }
+
+func getExtendedDOT(mbr *structures.MBR, extended *structures.PARTITION, pathDisk string) (string, error) {
+	dotContent := ""
+	tamanoTotalDisco := float64(mbr.Mbr_size)
+	ebrs, err := mbr.GetEBRs(pathDisk)
+	if err != nil {
+		return "", err
+	}
+	ebrSize := int32(binary.Size(structures.EBR{}))
+	cursor := extended.Part_start
+	for _, ebr := range ebrs {
+		if ebr.Ebr_start > cursor {
+			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+			`, getNumberNode(), "Libre", float64(ebr.Ebr_start-cursor)/tamanoTotalDisco*100)
+		}
+		if ebr.IsEmpty() {
+			cursor = ebr.Ebr_start + ebrSize
+			continue
+		}
+		dotContent += fmt.Sprintf(`node%d[shape=record, label="%s"];
+			`, getNumberNode(), "EBR")
+		dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%s\n%.1f%%"];
+			`, getNumberNode(), "Logica", ebr.GetName(), float64(ebr.Ebr_size)/tamanoTotalDisco*100)
+		cursor = ebr.Ebr_start + ebr.Ebr_size
+	}
+	extendedEnd := extended.Part_start + extended.Part_size
+	if extendedEnd > cursor {
+		dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+			`, getNumberNode(), "Libre", float64(extendedEnd-cursor)/tamanoTotalDisco*100)
+	}
+	return dotContent, nil
+}
@@ -22,7 +22,11 @@ func ReportInode(superBlock *structures.SuperBlock, diskPath, path string) error
 		rankdir=LR;
     `
 
-	for i := int32(0); i < superBlock.S_inodes_count; i++ {
+	usedInodes, err := superBlock.GetUsedInodes(diskPath)
+	if err != nil {
+		return err
+	}
+	for index, i := range usedInodes {
 		inode := &structures.Inode{}
 		err := inode.Deserialize(diskPath, int64(superBlock.S_inode_start+(i*superBlock.S_inode_size)))
 		if err != nil {
@@ -48,20 +52,22 @@ func ReportInode(superBlock *structures.SuperBlock, diskPath, path string) error
             `, i, i, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), string(inode.I_perm[:]))
 
 		for j, block := range inode.I_block {
-			if j > 13 {
+			if j >= structures.DirectPointers {
 				break
 			}
 			dotContent += fmt.Sprintf("<tr><td BGCOLOR=\"#bbccaa\">%d</td><td>%d</td></tr>", j+1, block)
 		}
 
 		dotContent += fmt.Sprintf(`
-                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUE INDIRECTO</td></tr>
-                <tr><td BGCOLOR="#bbccaa">%d</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUES INDIRECTOS</td></tr>
+                <tr><td BGCOLOR="#bbccaa">simple</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#bbccaa">doble</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#bbccaa">triple</td><td>%d</td></tr>
             </table>>];
-        `, 15, inode.I_block[14])
+        `, inode.I_block[structures.SingleIndirect], inode.I_block[structures.DoubleIndirect], inode.I_block[structures.TripleIndirect])
 
-		if i < superBlock.S_inodes_count-1 {
-			dotContent += fmt.Sprintf("inode%d -> inode%d;\n", i, i+1)
+		if index < len(usedInodes)-1 {
+			dotContent += fmt.Sprintf("inode%d -> inode%d;\n", i, usedInodes[index+1])
 		}
 	}
 	dotContent += "}"
@@ -42,54 +42,26 @@ func ReportLs(path string, pathToGetInfo string) error {
 	}
 
 	// Contenido
-	for i, blockIndex := range inodoBase.I_block {
-		if blockIndex == -1 {
-			continue
+	blocks, err := superBlock.GetInodeBlocks(diskPath, inodoBase)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range blocks {
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+		if err != nil {
+			return err
 		}
-		if i >= 14 {
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
-			if err != nil {
-				return err
-			}
-			for neoIndex := 0; neoIndex < len(pointerBlock.P_pointers); neoIndex++ {
-				if pointerBlock.P_pointers[neoIndex] == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(pointerBlock.P_pointers[neoIndex]*superBlock.S_block_size)))
-				if err != nil {
-					return err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					if content.B_inodo == -1 {
-						continue
-					}
-					temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath)
-					if err != nil {
-						return err
-					}
-					dotContent += temp
-				}
+		for i := 2; i < len(block.B_content); i++ {
+			content := block.B_content[i]
+			if content.B_inodo == -1 {
+				continue
 			}
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+			temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath)
 			if err != nil {
 				return err
 			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				if content.B_inodo == -1 {
-					continue
-				}
-				temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath)
-				if err != nil {
-					return err
-				}
-				dotContent += temp
-			}
+			dotContent += temp
 		}
 	}
 
@@ -157,67 +129,19 @@ func getInode(sb *structures.SuperBlock, inodeIndex int32, diskPath string, pare
 	if len(parentsDir) == 0 {
 		return inode, inodeIndex, nil
 	}
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		if i >= 14 {
-			inderctNode := &structures.PointerBlock{}
-			err := inderctNode.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return nil, 0, err
-			}
-			for _, value := range inderctNode.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
-				if err != nil {
-					return nil, 0, err
-				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if content.B_inodo == -1 {
-						continue
-					}
-					parentDir, err := utils.First(parentsDir)
-					if err != nil {
-						return nil, 0, err
-					}
-
-					contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-					parentDirName := strings.Trim(parentDir, "\x00 ")
-					if strings.EqualFold(contentName, parentDirName) {
-						return getInode(sb, content.B_inodo, diskPath, utils.RemoveElement(parentsDir, 0))
-					}
-				}
-			}
-
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return nil, 0, err
-			}
-			for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-				content := block.B_content[indexContent]
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return nil, 0, err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					return getInode(sb, content.B_inodo, diskPath, utils.RemoveElement(parentsDir, 0))
-				}
-			}
-		}
-
+	if inode.I_type[0] != '0' {
+		return nil, 0, errors.New("no existe la ruta especificada")
+	}
+	parentDir, err := utils.First(parentsDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	childIndex, err := sb.FindEntryInFolder(diskPath, inodeIndex, parentDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	if childIndex != -1 {
+		return getInode(sb, childIndex, diskPath, utils.RemoveElement(parentsDir, 0))
 	}
 	return nil, 0, errors.New("no existe la ruta especificada")
 }
@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-func ReportMBR(mbr *structures.MBR, path string, idDisk string) error {
+func ReportMBR(mbr *structures.MBR, path string, idDisk string, diskPath string) error {
 	err := utils.CreateParentDirs(path)
 	if err != nil {
 		return err
@@ -50,7 +50,24 @@ func ReportMBR(mbr *structures.MBR, path string, idDisk string) error {
 			`, i+1, partStatus, partType, partFit, part.Part_start, part.Part_size, partName)
 
 		if part.Part_type[0] == 'E' {
-			dotContent += ""
+			ebrs, err := mbr.GetEBRs(diskPath)
+			if err != nil {
+				return err
+			}
+			for _, ebr := range ebrs {
+				if ebr.IsEmpty() {
+					continue
+				}
+				dotContent += fmt.Sprintf(`
+				<tr><td colspan="2" BGCOLOR="#bbaacc"> EBR %s </td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_status</td><td>%c</td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_next</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_fit</td><td>%c</td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_start</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_size</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbaacc">part_name</td><td>%s</td></tr>
+			`, ebr.GetName(), rune(ebr.Ebr_status[0]), ebr.Ebr_next, rune(ebr.Ebr_fit[0]), ebr.Ebr_start, ebr.Ebr_size, ebr.GetName())
+			}
 		}
 	}
 
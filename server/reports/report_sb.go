@@ -43,8 +43,10 @@ func ReportSuperBlock(sb *structures.SuperBlock, diskPath, path string) error {
                 <tr><td BGCOLOR="#aaccbb">S_bm_block_start</td><td>%d</td></tr>
                 <tr><td BGCOLOR="#aaccbb">S_inode_start</td><td>%d</td></tr>
                 <tr><td BGCOLOR="#aaccbb">S_block_start</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#aaccbb">S_fit</td><td>%s</td></tr>
+                <tr><td BGCOLOR="#aaccbb">S_version</td><td>%d</td></tr>
 				 </table>>];
-            `, sb.S_filesystem_type, sb.S_inodes_count, sb.S_blocks_count, sb.S_free_inodes_count, sb.S_free_blocks_count, mtime, umtime, sb.S_mnt_count, sb.S_inode_size, sb.S_block_size, sb.S_first_ino, sb.S_first_blo, sb.S_bm_inode_start, sb.S_bm_block_start, sb.S_inode_start, sb.S_block_start)
+            `, sb.S_filesystem_type, sb.S_inodes_count, sb.S_blocks_count, sb.S_free_inodes_count, sb.S_free_blocks_count, mtime, umtime, sb.S_mnt_count, sb.S_inode_size, sb.S_block_size, sb.S_first_ino, sb.S_first_blo, sb.S_bm_inode_start, sb.S_bm_block_start, sb.S_inode_start, sb.S_block_start, string(sb.S_fit[:]), sb.S_version)
 
 	dotContent += "}"
 	dotFile, err := os.Create(dotFileName)
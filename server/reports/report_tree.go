@@ -83,17 +83,19 @@ func getInodeDOT(sb *structures.SuperBlock, inode *structures.Inode, diskPath st
 		<tr><td  colspan="2">BLOQUES DIRECTOS</td></tr>
 	`, nodoActual, numberInode, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), string(inode.I_perm[:]))
 	for j, block := range inode.I_block {
-		if j > 13 {
+		if j >= structures.DirectPointers {
 			break
 		}
 		dotContent += fmt.Sprintf("<tr><td >%d</td><td>%d</td></tr>", j+1, block)
 	}
 
 	dotContent += fmt.Sprintf(`
-			<tr><td  colspan="2">BLOQUE INDIRECTO</td></tr>
-			<tr><td >%d</td><td>%d</td></tr>
+			<tr><td  colspan="2">BLOQUES INDIRECTOS</td></tr>
+			<tr><td >simple</td><td>%d</td></tr>
+			<tr><td >doble</td><td>%d</td></tr>
+			<tr><td >triple</td><td>%d</td></tr>
 		</table>>];
-	`, 15, inode.I_block[14])
+	`, inode.I_block[structures.SingleIndirect], inode.I_block[structures.DoubleIndirect], inode.I_block[structures.TripleIndirect])
 
 	// Hacer las direcciones de todos los nodos q este kabron saca del Iblock
 	if !isTheRoot {
@@ -103,13 +105,13 @@ func getInodeDOT(sb *structures.SuperBlock, inode *structures.Inode, diskPath st
 
 	for i, value := range inode.I_block {
 		if value != -1 {
-			if i >= 14 {
+			if i >= structures.DirectPointers {
 				block := &structures.PointerBlock{}
 				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
 				if err != nil {
 					return "", err
 				}
-				temp, err := getPointerBlockDOT(sb, block, nodoActual, int(value), diskPath, inode.I_type[0])
+				temp, err := getPointerBlockDOT(sb, block, nodoActual, int(value), diskPath, inode.I_type[0], structures.PointerLevel(i))
 				if err != nil {
 					return "", err
 				}
@@ -187,7 +189,8 @@ func getFileBlockDOT(block *structures.FileBlock, nodoPadre int, blockIndex int)
 	return dotContent, nil
 }
 
-func getPointerBlockDOT(sb *structures.SuperBlock, block *structures.PointerBlock, nodoPadre int, blockIndex int, diskPath string, tipoInodo byte) (string, error) {
+// level indica cuantos niveles de apuntadores faltan para llegar a los bloques de datos
+func getPointerBlockDOT(sb *structures.SuperBlock, block *structures.PointerBlock, nodoPadre int, blockIndex int, diskPath string, tipoInodo byte, level int) (string, error) {
 	nodoActual := getNode()
 	dotContent := fmt.Sprintf(`node%d[fillcolor="#f7dc6f" style=filled shape=record label="Bloque Apuntador%d\n`, nodoActual, blockIndex)
 	for index, value := range block.P_pointers {
@@ -204,7 +207,18 @@ func getPointerBlockDOT(sb *structures.SuperBlock, block *structures.PointerBloc
 		if indexInode == -1 {
 			continue
 		}
-		if tipoInodo == '0' {
+		if level > 1 {
+			block := &structures.PointerBlock{}
+			err := block.Deserialize(diskPath, int64(sb.S_block_start+(indexInode*sb.S_block_size)))
+			if err != nil {
+				return "", err
+			}
+			temp, err := getPointerBlockDOT(sb, block, nodoActual, int(indexInode), diskPath, tipoInodo, level-1)
+			if err != nil {
+				return "", err
+			}
+			dotContent += temp
+		} else if tipoInodo == '0' {
 			block := &structures.FolderBlock{}
 			err := block.Deserialize(diskPath, int64(sb.S_block_start+(indexInode*sb.S_block_size)))
 			if err != nil {
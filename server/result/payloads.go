@@ -0,0 +1,98 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+)
+
+type MountedPartition struct {
+	ID   string `json:"id"`
+	Disk string `json:"disk"`
+	Name string `json:"name"`
+}
+
+type MountedList struct {
+	Partitions []MountedPartition `json:"partitions"`
+}
+
+func (m *MountedList) Message() string {
+	if len(m.Partitions) == 0 {
+		return "No hay particiones montadas"
+	}
+	ids := make([]string, 0, len(m.Partitions))
+	for _, partition := range m.Partitions {
+		ids = append(ids, partition.ID)
+	}
+	return fmt.Sprintf("Particiones montadas: %s", strings.Join(ids, ", "))
+}
+
+type FindHits struct {
+	Path string   `json:"path"`
+	Name string   `json:"name"`
+	Hits []string `json:"hits"`
+	Tree string   `json:"-"`
+}
+
+func (f *FindHits) Message() string {
+	return fmt.Sprintf("FIND: %s\n%s", f.Path, f.Tree)
+}
+
+type CatFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type CatContents struct {
+	Files []CatFile `json:"files"`
+}
+
+func (c *CatContents) Message() string {
+	var content string
+	for _, file := range c.Files {
+		content += file.Content + "\n"
+	}
+	return content
+}
+
+// Salida de help: la lista de comandos o el uso de uno
+type Help struct {
+	Command string `json:"command,omitempty"`
+	Text    string `json:"text"`
+}
+
+func (h *Help) Message() string {
+	return h.Text
+}
+
+type PartitionInfo struct {
+	ID      string `json:"id"`
+	Disk    string `json:"disk"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Fit     string `json:"fit"`
+	Start   int32  `json:"start"`
+	Size    int32  `json:"size"`
+	Mounted bool   `json:"mounted"`
+}
+
+func (p *PartitionInfo) Message() string {
+	return fmt.Sprintf("MOUNT: %s montada exitosamente con id %s", p.Name, p.ID)
+}
+
+// Resumen de un script de execute. Los resultados de cada comando se imprimen
+// por separado (ver Result.Lines), aqui solo van los totales.
+type Script struct {
+	Path      string   `json:"path"`
+	Total     int      `json:"total"`
+	Failed    int      `json:"failed"`
+	Strict    bool     `json:"strict"`
+	StoppedAt int      `json:"stopped_at,omitempty"` //Linea donde se detuvo en modo strict
+	Results   []Result `json:"-"`
+}
+
+func (s *Script) Message() string {
+	if s.StoppedAt > 0 {
+		return fmt.Sprintf("EXECUTE: %s detenido en la linea %d (%d comandos, %d con error)", s.Path, s.StoppedAt, s.Total, s.Failed)
+	}
+	return fmt.Sprintf("EXECUTE: %s ejecutado (%d comandos, %d con error)", s.Path, s.Total, s.Failed)
+}
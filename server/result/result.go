@@ -0,0 +1,83 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Resultado de un comando. Data solo viene en los comandos que devuelven
+// informacion que se puede consumir desde otras herramientas.
+type Result struct {
+	Status  string `json:"status"`
+	Command string `json:"command"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Lo implementan los datos tipados que devuelven los comandos, Message es el
+// texto que se muestra en consola
+type Payload interface {
+	Message() string
+}
+
+// Arma el resultado con lo que devolvio el parser del comando: un string, un
+// Payload o un error
+func New(command string, output any, err error) Result {
+	if err != nil {
+		return Result{Status: StatusError, Command: command, Message: err.Error()}
+	}
+	switch value := output.(type) {
+	case nil:
+		return Result{Status: StatusOK, Command: command}
+	case string:
+		return Result{Status: StatusOK, Command: command, Message: value}
+	case *Script:
+		// Un execute con -strict que se detuvo no termino su trabajo
+		if value.StoppedAt > 0 {
+			return Result{Status: StatusError, Command: command, Message: value.Message(), Data: value}
+		}
+		return Result{Status: StatusOK, Command: command, Message: value.Message(), Data: value}
+	case Payload:
+		return Result{Status: StatusOK, Command: command, Message: value.Message(), Data: value}
+	default:
+		return Result{Status: StatusOK, Command: command, Message: fmt.Sprintf("%v", value)}
+	}
+}
+
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+func (r Result) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return fmt.Errorf("%s", r.Message)
+}
+
+// Resultado y los de los comandos de sus scripts, en el orden en que se
+// ejecutaron, para imprimir una linea por comando
+func (r Result) Lines() []Result {
+	script, ok := r.Data.(*Script)
+	if !ok {
+		return []Result{r}
+	}
+	lines := make([]Result, 0, len(script.Results)+1)
+	for _, inner := range script.Results {
+		lines = append(lines, inner.Lines()...)
+	}
+	return append(lines, r)
+}
+
+func (r Result) JSON() string {
+	content, err := json.Marshal(r)
+	if err != nil {
+		content, _ = json.Marshal(Result{Status: StatusError, Command: r.Command, Message: err.Error()})
+	}
+	return string(content)
+}
@@ -0,0 +1,147 @@
+package stores
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"server/config"
+	"server/structures"
+	"server/utils"
+	"strings"
+)
+
+// Archivo donde se guardan los discos conocidos y la sesion entre ejecuciones.
+// Los IDs de montaje no se guardan aqui, se leen de los MBR y EBR de cada disco.
+func StateFile() string {
+	return filepath.Join(config.Current.DiskDir, ".mia_state.json")
+}
+
+type state struct {
+	Disks            map[string]string `json:"disks"`   //path:nombre
+	Letters          map[string]string `json:"letters"` //path:letra
+	LogedIdPartition string            `json:"loged_id_partition"`
+	LogedUser        string            `json:"loged_user"`
+	LogedUserID      int32             `json:"loged_user_id"`
+	LogedUserGroupID int32             `json:"loged_user_group_id"`
+	ExtraGroupIDs    []int32           `json:"extra_group_ids,omitempty"`
+}
+
+func SaveState() error {
+	current := state{
+		Disks:            LoadedDiskPaths,
+		Letters:          utils.PathToLetter,
+		LogedIdPartition: LogedIdPartition,
+		LogedUser:        LogedUser,
+		LogedUserID:      utils.LogedUserID,
+		LogedUserGroupID: utils.LogedUserGroupID,
+		ExtraGroupIDs:    utils.LogedUserExtraGroupIDs,
+	}
+	content, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = utils.CreateParentDirs(StateFile())
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(StateFile(), content, 0644)
+	if err != nil {
+		return fmt.Errorf("no se pudo guardar el estado: %v", err)
+	}
+	return nil
+}
+
+// Reconstruye la tabla de montaje a partir de los discos del estado guardado y
+// de los que esten en la carpeta de discos. La sesion solo se restaura si su
+// particion sigue montada.
+func LoadState() error {
+	saved := state{}
+	content, err := os.ReadFile(StateFile())
+	if err == nil {
+		err = json.Unmarshal(content, &saved)
+		if err != nil {
+			return fmt.Errorf("el archivo de estado %s esta corrupto: %v", StateFile(), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	paths := make(map[string]bool)
+	for key, value := range saved.Disks {
+		// Los estados anteriores guardaban nombre:path
+		path := key
+		if !filepath.IsAbs(key) {
+			path = value
+		}
+		paths[AbsDiskPath(path)] = true
+	}
+	letters := make(map[string]string)
+	for path, letter := range saved.Letters {
+		letters[AbsDiskPath(path)] = letter
+		paths[AbsDiskPath(path)] = true
+	}
+	utils.RestoreLetters(letters)
+	for _, pattern := range []string{"*.dsk", "*.mia"} {
+		matches, _ := filepath.Glob(filepath.Join(config.Current.DiskDir, pattern))
+		for _, path := range matches {
+			paths[AbsDiskPath(path)] = true
+		}
+	}
+
+	for path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		RegisterDisk(path)
+		restoreMountedPartitions(path)
+	}
+
+	if saved.LogedIdPartition != "" && MountedPartitions[saved.LogedIdPartition] != "" {
+		LogedIdPartition = saved.LogedIdPartition
+		LogedUser = saved.LogedUser
+		utils.LogedUserID = saved.LogedUserID
+		utils.LogedUserGroupID = saved.LogedUserGroupID
+		utils.LogedUserExtraGroupIDs = saved.ExtraGroupIDs
+	}
+	return nil
+}
+
+// Registra las particiones que el disco marca como montadas, asi se pueden
+// desmontar y mount no repite sus IDs
+func restoreMountedPartitions(path string) {
+	var mbr structures.MBR
+	err := mbr.DeserializeMBR(path)
+	if err != nil {
+		return
+	}
+	ids := make([]string, 0)
+	for _, partition := range mbr.Mbr_partitions {
+		if partition.Part_status[0] == '1' {
+			ids = append(ids, strings.Trim(string(partition.Part_id[:]), "\x00 "))
+		}
+	}
+	logicals, err := mbr.GetLogicalPartitions(path)
+	if err == nil {
+		for _, logical := range logicals {
+			if logical.Ebr_status[0] == '1' {
+				ids = append(ids, strings.Trim(string(logical.Ebr_id[:]), "\x00 "))
+			}
+		}
+	}
+
+	// Se registran aunque el sufijo no sea el configurado ahora, si no
+	// quedarian montadas en el disco sin poder desmontarse
+	for _, id := range ids {
+		if len(id) < 2 {
+			continue
+		}
+		if other, exists := MountedPartitions[id]; exists && other != path {
+			continue
+		}
+		MountedPartitions[id] = path
+		if _, exists := utils.PathToLetter[path]; !exists {
+			utils.RestoreLetters(map[string]string{path: id[:1]})
+		}
+	}
+}
@@ -3,24 +3,95 @@ package stores
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"server/config"
 	"server/structures"
 	"server/utils"
 	"strings"
 )
 
-const Carnet string = "05"                                                          //2023007705
-const PathDisk string = "/home/vela/Documentos/MIA/MIA_P1_202307705_1VAC1S2025/test" //FIXME cambiar el path
-
 var (
 	MountedPartitions map[string]string = make(map[string]string) //ID:path
 	LogedIdPartition  string            = ""
 	LogedUser         string            = ""
-	LoadedDiskPaths   map[string]string = make(map[string]string) //Nombre:path
+	LoadedDiskPaths   map[string]string = make(map[string]string) //path absoluto:nombre
 )
 
 func GetPathDisk(name string) string {
-	return fmt.Sprintf(`%s/%s.dsk`, PathDisk, name)
+	return AbsDiskPath(filepath.Join(config.Current.DiskDir, name+".dsk"))
+}
+
+// Ruta absoluta y limpia del disco, con ella se registra en LoadedDiskPaths y
+// en la tabla de montaje para que dos rutas al mismo archivo sean el mismo disco
+func AbsDiskPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+func RegisterDisk(path string) {
+	path = AbsDiskPath(path)
+	LoadedDiskPaths[path] = utils.GetNameByPath(path)
+}
+
+// Busca un disco registrado por su ruta o por su nombre. Si hay discos con el
+// mismo nombre en carpetas distintas hay que usar la ruta.
+func FindDisk(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		if _, exists := LoadedDiskPaths[AbsDiskPath(name)]; exists {
+			return AbsDiskPath(name), nil
+		}
+	}
+	found := ""
+	for path, diskName := range LoadedDiskPaths {
+		if diskName != name {
+			continue
+		}
+		if found != "" {
+			return "", fmt.Errorf("hay varios discos con el nombre %s, use su ruta", name)
+		}
+		found = path
+	}
+	if found == "" {
+		return "", fmt.Errorf("el disco no existe: %s", name)
+	}
+	return found, nil
+}
+
+// Resuelve el disco a partir de -path o, si no viene, del alias -driveletter
+func ResolveDiskPath(path, driveLetter string) (string, error) {
+	if path != "" && driveLetter != "" {
+		return "", errors.New("no se puede usar -path y -driveletter al mismo tiempo")
+	}
+	if path == "" {
+		if driveLetter == "" {
+			return "", errors.New("faltan parámetros requeridos: -path")
+		}
+		return GetPathDisk(strings.ToUpper(driveLetter)), nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".mia" && ext != ".dsk" {
+		return "", errors.New("el disco debe tener extension .mia o .dsk")
+	}
+	return AbsDiskPath(path), nil
+}
+
+// Siguiente letra cuyo disco todavia no existe, asi mkdisk sin -path no pisa
+// los discos que quedaron de ejecuciones anteriores
+func NextDiskPath() (string, error) {
+	for {
+		letter := utils.GetLetterToDisk()
+		if letter == "" {
+			return "", errors.New("no hay más letras disponibles para los discos")
+		}
+		path := GetPathDisk(letter)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path, nil
+		}
+	}
 }
 
 func GetMountedPartition(id string) (*structures.PARTITION, string, error) {
@@ -35,7 +106,7 @@ func GetMountedPartition(id string) (*structures.PARTITION, string, error) {
 		return nil, "", err
 	}
 
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.GetAnyPartitionByID(path, id)
 	if partition == nil {
 		return nil, "", err
 	}
@@ -47,15 +118,10 @@ func DeleteMountedPartitions(path string) {
 	for key, value := range MountedPartitions {
 		if value == path {
 			delete(MountedPartitions, key)
-			delete(utils.PathToPartitionCount, path)
 			// delete(utils.PathToLetter, path)
 		}
 	}
-	for key, value := range LoadedDiskPaths {
-		if value == path {
-			delete(LoadedDiskPaths, key)
-		}
-	}
+	delete(LoadedDiskPaths, AbsDiskPath(path))
 }
 
 func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock, string, error) {
@@ -69,7 +135,7 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock,
 	if err != nil {
 		return nil, nil, "", err
 	}
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.GetAnyPartitionByID(path, id)
 	if partition == nil {
 		return nil, nil, "", err
 	}
@@ -104,7 +170,7 @@ func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structur
 		return nil, nil, "", err
 	}
 
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.GetAnyPartitionByID(path, id)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -115,6 +181,10 @@ func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structur
 	if err != nil {
 		return nil, nil, "", err
 	}
+	err = sb.CheckVersion()
+	if err != nil {
+		return nil, nil, "", err
+	}
 
 	return &sb, partition, path, nil
 }
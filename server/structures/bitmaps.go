@@ -2,11 +2,12 @@ package structures
 
 import (
 	"encoding/binary"
+	"errors"
 	"os"
 )
 
-func (sb *SuperBlock)CreateBitMaps(path string) error {
-	file, err:= os.OpenFile(path, os.O_WRONLY| os.O_CREATE, 0644)
+func (sb *SuperBlock) CreateBitMaps(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return err
 	}
@@ -16,9 +17,9 @@ func (sb *SuperBlock)CreateBitMaps(path string) error {
 	if err != nil {
 		return err
 	}
-	
+
 	buffer := make([]byte, sb.S_free_inodes_count)
-	for i := range buffer{
+	for i := range buffer {
 		buffer[i] = '0'
 	}
 	err = binary.Write(file, binary.LittleEndian, buffer)
@@ -43,40 +44,241 @@ func (sb *SuperBlock)CreateBitMaps(path string) error {
 
 }
 
-func (sb *SuperBlock)UpdateBitmapInode(path string) error  {
+/*
+Bitmap de inodos:  '0' libre, '1' ocupado
+Bitmap de bloques: 'O' libre, 'X' ocupado
+*/
+func (sb *SuperBlock) TotalInodes() int32 {
+	return sb.S_inodes_count + sb.S_free_inodes_count
+}
+
+func (sb *SuperBlock) TotalBlocks() int32 {
+	return sb.S_blocks_count + sb.S_free_blocks_count
+}
+
+func (sb *SuperBlock) ReadBitmapInode(path string) ([]byte, error) {
+	return readBitmap(path, sb.S_bm_inode_start, sb.TotalInodes())
+}
+
+func (sb *SuperBlock) ReadBitmapBlock(path string) ([]byte, error) {
+	return readBitmap(path, sb.S_bm_block_start, sb.TotalBlocks())
+}
+
+func readBitmap(path string, start, size int32) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, size)
+	_, err = file.ReadAt(buffer, int64(start))
+	if err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+func writeBitmapByte(path string, offset int64, value byte) error {
 	file, err := os.OpenFile(path, os.O_RDWR, 0644)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	_, err = file.Seek(int64(sb.S_bm_inode_start)+int64(sb.S_inodes_count), 0)
+	_, err = file.WriteAt([]byte{value}, offset)
+	return err
+}
+
+// Busca un espacio libre en el bitmap segun el ajuste (F, B o W). Para best y
+// worst se toma el inicio del hueco de libres consecutivos mas chico o mas grande.
+func findFreeSlot(bitmap []byte, free byte, fit byte) int32 {
+	chosen, chosenSize := int32(-1), int32(0)
+	for i := int32(0); i < int32(len(bitmap)); i++ {
+		if bitmap[i] != free {
+			continue
+		}
+		start := i
+		for i < int32(len(bitmap)) && bitmap[i] == free {
+			i++
+		}
+		size := i - start
+		switch fit {
+		case 'B', 'b':
+			if chosen == -1 || size < chosenSize {
+				chosen, chosenSize = start, size
+			}
+		case 'W', 'w':
+			if chosen == -1 || size > chosenSize {
+				chosen, chosenSize = start, size
+			}
+		default:
+			return start
+		}
+	}
+	return chosen
+}
+
+// Reserva un inodo libre, lo marca en el bitmap y actualiza los contadores
+func (sb *SuperBlock) AllocateInode(path string) (int32, error) {
+	bitmap, err := sb.ReadBitmapInode(path)
+	if err != nil {
+		return -1, err
+	}
+	index := findFreeSlot(bitmap, '0', sb.S_fit[0])
+	if index == -1 {
+		return -1, errors.New("no hay inodos libres en la particion")
+	}
+	err = sb.UpdateBitmapInode(path, index, true)
+	if err != nil {
+		return -1, err
+	}
+	bitmap[index] = '1'
+	sb.S_inodes_count++
+	sb.S_free_inodes_count--
+	sb.S_first_ino = sb.S_inode_start + firstFree(bitmap, '0')*sb.S_inode_size
+	return index, nil
+}
+
+// Reserva un bloque libre, lo marca en el bitmap y actualiza los contadores
+func (sb *SuperBlock) AllocateBlock(path string) (int32, error) {
+	bitmap, err := sb.ReadBitmapBlock(path)
+	if err != nil {
+		return -1, err
+	}
+	index := findFreeSlot(bitmap, 'O', sb.S_fit[0])
+	if index == -1 {
+		return -1, errors.New("no hay bloques libres en la particion")
+	}
+	err = sb.UpdateBitmapBlock(path, index, true)
+	if err != nil {
+		return -1, err
+	}
+	bitmap[index] = 'X'
+	sb.S_blocks_count++
+	sb.S_free_blocks_count--
+	sb.S_first_blo = sb.S_block_start + firstFree(bitmap, 'O')*sb.S_block_size
+	return index, nil
+}
+
+func (sb *SuperBlock) FreeInode(path string, index int32) error {
+	if index < 0 || index >= sb.TotalInodes() {
+		return errors.New("indice de inodo fuera de rango")
+	}
+	bitmap, err := sb.ReadBitmapInode(path)
 	if err != nil {
 		return err
 	}
-	_, err = file.Write([]byte{'1'})
+	if bitmap[index] == '0' {
+		return nil
+	}
+	err = sb.UpdateBitmapInode(path, index, false)
 	if err != nil {
 		return err
 	}
+	bitmap[index] = '0'
+	sb.S_inodes_count--
+	sb.S_free_inodes_count++
+	sb.S_first_ino = sb.S_inode_start + firstFree(bitmap, '0')*sb.S_inode_size
 	return nil
 }
 
-func (sb *SuperBlock) UpdateBitmapBlock(path string) error {
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+func (sb *SuperBlock) FreeBlock(path string, index int32) error {
+	if index < 0 || index >= sb.TotalBlocks() {
+		return errors.New("indice de bloque fuera de rango")
+	}
+	bitmap, err := sb.ReadBitmapBlock(path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	_, err = file.Seek(int64(sb.S_bm_block_start)+int64(sb.S_blocks_count), 0)
+	if bitmap[index] == 'O' {
+		return nil
+	}
+	err = sb.UpdateBitmapBlock(path, index, false)
 	if err != nil {
 		return err
 	}
+	bitmap[index] = 'O'
+	sb.S_blocks_count--
+	sb.S_free_blocks_count++
+	sb.S_first_blo = sb.S_block_start + firstFree(bitmap, 'O')*sb.S_block_size
+	return nil
+}
 
-	_, err = file.Write([]byte{'X'})
+// Libera el inodo junto con sus bloques de datos y de apuntadores. Si es una
+// carpeta no recorre su contenido, eso le toca a quien la elimina.
+func (sb *SuperBlock) ReleaseInode(path string, index int32) error {
+	inode := &Inode{}
+	err := inode.Deserialize(path, int64(sb.S_inode_start+(index*sb.S_inode_size)))
 	if err != nil {
 		return err
 	}
+	err = sb.ReleaseInodeBlocks(path, inode)
+	if err != nil {
+		return err
+	}
+	return sb.FreeInode(path, index)
+}
 
+// Libera los bloques de datos y de apuntadores del inodo y deja I_block vacio.
+// No serializa el inodo.
+func (sb *SuperBlock) ReleaseInodeBlocks(path string, inode *Inode) error {
+	blocks, err := sb.GetInodeBlocks(path, inode)
+	if err != nil {
+		return err
+	}
+	pointers, err := sb.GetInodePointerBlocks(path, inode)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range append(blocks, pointers...) {
+		err = sb.FreeBlock(path, blockIndex)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Indices de los inodos marcados como ocupados en el bitmap
+func (sb *SuperBlock) GetUsedInodes(path string) ([]int32, error) {
+	bitmap, err := sb.ReadBitmapInode(path)
+	if err != nil {
+		return nil, err
+	}
+	used := make([]int32, 0)
+	for i, value := range bitmap {
+		if value == '1' {
+			used = append(used, int32(i))
+		}
+	}
+	return used, nil
+}
+
+func firstFree(bitmap []byte, free byte) int32 {
+	for i, value := range bitmap {
+		if value == free {
+			return int32(i)
+		}
+	}
+	return int32(len(bitmap))
+}
+
+func (sb *SuperBlock) UpdateBitmapInode(path string, index int32, used bool) error {
+	value := byte('0')
+	if used {
+		value = '1'
+	}
+	return writeBitmapByte(path, int64(sb.S_bm_inode_start)+int64(index), value)
+}
+
+func (sb *SuperBlock) UpdateBitmapBlock(path string, index int32, used bool) error {
+	value := byte('O')
+	if used {
+		value = 'X'
+	}
+	return writeBitmapByte(path, int64(sb.S_bm_block_start)+int64(index), value)
+}
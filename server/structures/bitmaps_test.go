@@ -0,0 +1,31 @@
+package structures
+
+import "testing"
+
+func TestFindFreeSlot(t *testing.T) {
+	tests := []struct {
+		name   string
+		bitmap string
+		fit    byte
+		want   int32
+	}{
+		{"primero", "XOOXOXOOO", 'F', 1},
+		{"mejor", "XOOXOXOOO", 'B', 4},
+		{"peor", "XOOXOXOOO", 'W', 6},
+		{"minusculas", "XOOXOXOOO", 'b', 4},
+		{"empate en mejor toma el primero", "OXOXOO", 'B', 0},
+		{"empate en peor toma el primero", "OOXOO", 'W', 0},
+		{"libre al final", "XXXO", 'W', 3},
+		{"sin libres", "XXXX", 'F', -1},
+		{"sin libres con mejor", "XXXX", 'B', -1},
+		{"bitmap vacio", "", 'W', -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := findFreeSlot([]byte(test.bitmap), 'O', test.fit)
+			if got != test.want {
+				t.Errorf("findFreeSlot(%s, %c) = %d, se esperaba %d", test.bitmap, test.fit, got, test.want)
+			}
+		})
+	}
+}
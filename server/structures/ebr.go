@@ -0,0 +1,240 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// El EBR se escribe al inicio de cada particion logica (Ebr_start) y los datos
+// de la particion empiezan justo despues de el.
+type EBR struct {
+	Ebr_status [1]byte
+	Ebr_fit    [1]byte
+	Ebr_start  int32
+	Ebr_size   int32
+	Ebr_next   int32
+	Ebr_name   [16]byte
+	Ebr_id     [4]byte
+}
+
+/*
+Ebr Status:
+
+	N: Disponible (solo el primer EBR de la extendida puede quedar asi)
+	0: Creado
+	1: Montado
+*/
+func (ebr *EBR) Serialize(path string, offset int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, 0)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(file, binary.LittleEndian, ebr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ebr *EBR) Deserialize(path string, offset int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, 0)
+	if err != nil {
+		return err
+	}
+
+	ebrSize := binary.Size(ebr)
+	if ebrSize <= 0 {
+		return fmt.Errorf("invalid EBR size: %d", ebrSize)
+	}
+
+	buffer := make([]byte, ebrSize)
+	_, err = file.Read(buffer)
+	if err != nil {
+		return err
+	}
+
+	reader := bytes.NewReader(buffer)
+	err = binary.Read(reader, binary.LittleEndian, ebr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ebr *EBR) CreateEBR(ebrStart, ebrSize, ebrNext int, ebrFit, ebrName string) {
+	ebr.Ebr_status[0] = '0'
+	ebr.Ebr_start = int32(ebrStart)
+	ebr.Ebr_size = int32(ebrSize)
+	ebr.Ebr_next = int32(ebrNext)
+
+	if len(ebrFit) > 0 {
+		ebr.Ebr_fit[0] = ebrFit[0]
+	}
+
+	ebr.Ebr_name = [16]byte{}
+	copy(ebr.Ebr_name[:], ebrName)
+	ebr.Ebr_id = [4]byte{}
+}
+
+// Deja el EBR como cabecera vacia pero conserva el enlace al siguiente
+func (ebr *EBR) Clean() {
+	ebr.Ebr_status[0] = 'N'
+	ebr.Ebr_fit[0] = 'N'
+	ebr.Ebr_size = 0
+	ebr.Ebr_name = [16]byte{'N'}
+	ebr.Ebr_id = [4]byte{'N'}
+}
+
+func (ebr *EBR) IsEmpty() bool {
+	return ebr.Ebr_status[0] == 'N' || ebr.Ebr_size <= 0
+}
+
+func (ebr *EBR) MountPartition(id string) {
+	ebr.Ebr_status[0] = '1'
+	ebr.Ebr_id = [4]byte{}
+	copy(ebr.Ebr_id[:], id)
+}
+
+func (ebr *EBR) GetName() string {
+	return strings.Trim(string(ebr.Ebr_name[:]), "\x00 ")
+}
+
+// Representa la particion logica como un PARTITION para que el resto de comandos
+// (mkfs, login, reportes) la traten igual que a una primaria.
+func (ebr *EBR) ToPartition() *PARTITION {
+	ebrSize := int32(binary.Size(EBR{}))
+	partition := &PARTITION{
+		Part_status:      ebr.Ebr_status,
+		Part_type:        [1]byte{'L'},
+		Part_fit:         ebr.Ebr_fit,
+		Part_start:       ebr.Ebr_start + ebrSize,
+		Part_size:        ebr.Ebr_size - ebrSize,
+		Part_name:        ebr.Ebr_name,
+		Part_correlative: -1,
+		Part_id:          ebr.Ebr_id,
+	}
+	return partition
+}
+
+func (ebr *EBR) PrintEBR() {
+	fmt.Printf("Ebr_status: %c\n", ebr.Ebr_status[0])
+	fmt.Printf("Ebr_fit: %c\n", ebr.Ebr_fit[0])
+	fmt.Printf("Ebr_start: %d\n", ebr.Ebr_start)
+	fmt.Printf("Ebr_size: %d\n", ebr.Ebr_size)
+	fmt.Printf("Ebr_next: %d\n", ebr.Ebr_next)
+	fmt.Printf("Ebr_name: %s\n", string(ebr.Ebr_name[:]))
+	fmt.Printf("Ebr_id: %s\n", string(ebr.Ebr_id[:]))
+}
+
+// Recorre la cadena de EBRs de la particion extendida. El primer EBR siempre
+// esta al inicio de la extendida, aunque este vacio.
+func (mbr *MBR) GetEBRs(path string) ([]EBR, error) {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return nil, err
+	}
+	var ebrs []EBR
+	visited := make(map[int32]bool)
+	offset := extended.Part_start
+	for offset != -1 {
+		if visited[offset] {
+			return nil, errors.New("la cadena de EBRs tiene un ciclo")
+		}
+		visited[offset] = true
+		if offset < extended.Part_start || offset >= extended.Part_start+extended.Part_size {
+			return nil, errors.New("la cadena de EBRs apunta fuera de la particion extendida")
+		}
+		ebr := EBR{}
+		err := ebr.Deserialize(path, int64(offset))
+		if err != nil {
+			return nil, err
+		}
+		ebrs = append(ebrs, ebr)
+		offset = ebr.Ebr_next
+	}
+	return ebrs, nil
+}
+
+// Devuelve solo las particiones logicas existentes (omite la cabecera vacia)
+func (mbr *MBR) GetLogicalPartitions(path string) ([]EBR, error) {
+	if !mbr.IsThereExtendedPartition() {
+		return make([]EBR, 0), nil
+	}
+	ebrs, err := mbr.GetEBRs(path)
+	if err != nil {
+		return nil, err
+	}
+	logicals := make([]EBR, 0)
+	for _, ebr := range ebrs {
+		if ebr.IsEmpty() {
+			continue
+		}
+		logicals = append(logicals, ebr)
+	}
+	return logicals, nil
+}
+
+func (mbr *MBR) GetLogicalPartitionByName(path, name string) (*EBR, error) {
+	logicals, err := mbr.GetLogicalPartitions(path)
+	if err != nil {
+		return nil, err
+	}
+	inputName := strings.Trim(name, "\x00 ")
+	for i := range logicals {
+		if strings.EqualFold(logicals[i].GetName(), inputName) {
+			return &logicals[i], nil
+		}
+	}
+	return nil, errors.New("particion logica no encontrada")
+}
+
+func (mbr *MBR) GetLogicalPartitionByID(path, id string) (*EBR, error) {
+	logicals, err := mbr.GetLogicalPartitions(path)
+	if err != nil {
+		return nil, err
+	}
+	inputID := strings.Trim(id, "\x00 ")
+	for i := range logicals {
+		if logicals[i].Ebr_status[0] != '1' {
+			continue
+		}
+		ebrID := strings.Trim(string(logicals[i].Ebr_id[:]), "\x00 ")
+		if strings.EqualFold(ebrID, inputID) {
+			return &logicals[i], nil
+		}
+	}
+	return nil, errors.New("partición no encontrada")
+}
+
+// Busca por ID tanto en las primarias del MBR como en las logicas de la extendida
+func (mbr *MBR) GetAnyPartitionByID(path, id string) (*PARTITION, error) {
+	partition, _, err := mbr.GetPartitionByID(id)
+	if err == nil {
+		return partition, nil
+	}
+	if !mbr.IsThereExtendedPartition() {
+		return nil, err
+	}
+	ebr, err := mbr.GetLogicalPartitionByID(path, id)
+	if err != nil {
+		return nil, err
+	}
+	return ebr.ToPartition(), nil
+}
@@ -4,233 +4,87 @@ import (
 	// structures "server/structures"
 
 	"errors"
+	"fmt"
 	utils "server/utils"
 	"strings"
 	"time"
 )
 
 func (sb *SuperBlock) createFolderInInode(path string, inodeIndex int32, parentsDir []string, destDir string, justSearchingAFile bool) error {
+	folderIndex, err := sb.findFolder(path, inodeIndex, parentsDir)
+	if err != nil {
+		return err
+	}
+	if folderIndex == -1 {
+		if justSearchingAFile {
+			return nil
+		}
+		return errors.New("ruta invalida, asegurese que exita la ruta antes")
+	}
 	inode := &Inode{}
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err = inode.Deserialize(path, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 	if err != nil {
 		return err
 	}
-	if inode.I_type[0] == '1' {
-		return nil
+	existing, err := sb.FindEntryInFolder(path, folderIndex, destDir)
+	if err != nil {
+		return err
+	}
+	if existing != -1 {
+		return errors.New("ya existe un directorio con el mismo nombre")
+	}
+	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("inaccesible por falta de permisos")
+	}
+	err = sb.checkSpaceForEntry(path, inode, 1)
+	if err != nil {
+		return err
+	}
+	newInodeIndex, err := sb.AllocateInode(path)
+	if err != nil {
+		return err
+	}
+	newBlockIndex, err := sb.AllocateBlock(path)
+	if err != nil {
+		return err
 	}
-	var inodoPadre int32
-	for i, blockIndex := range inode.I_block {
-		numeroApuntadorIndirect := sb.S_blocks_count
-		if blockIndex == -1 {
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if i >= 14 {
-				inode.I_block[i] = sb.S_blocks_count
-
-				pointerBlock := &PointerBlock{
-					P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-				}
-				err = pointerBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				//Bloque folder
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				inode.Serialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				flag, err := sb.folderFromAuntadorIndirecto13(path, inodeIndex, parentsDir, destDir, justSearchingAFile, numeroApuntadorIndirect, inodoPadre)
-				if err != nil {
-					return err
-				}
-				if flag {
-					return nil
-				}
-			} else {
-				inode.I_block[i] = sb.S_blocks_count
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-				inode.Serialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				return sb.createFolderInInode(path, inodeIndex, parentsDir, destDir, justSearchingAFile)
-			}
-		}
-
-		if i >= 14 {
-			flag, err := sb.folderFromAuntadorIndirecto13(path, inodeIndex, parentsDir, destDir, justSearchingAFile, blockIndex, inodoPadre)
-			if err != nil {
-				return err
-			}
-			if flag {
-				return nil
-			}
-
-		}
-		block := &FolderBlock{}
-		err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return err
-		}
-
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-
-			if len(parentsDir) != 0 {
-
-				if content.B_inodo == -1 {
-					continue
-				}
-
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.createFolderInInode(path, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, justSearchingAFile)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					return errors.New("ya existe un directorio con el mismo nombre")
-				}
-				if content.B_inodo != -1 {
-					tempContent := block.B_content[1]
-					inodoPadre = tempContent.B_inodo
-					continue
-				}
-				outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
-				if err != nil {
-					return err
-				}
-				if !outcome {
-					return errors.New("inaccesible por falta de permisos")
-				}
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-
-				block.B_content[indexContent] = content
-
-				err = block.Serialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'0'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-
-				err = folderInode.Serialize(path, int64(sb.S_first_ino))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapInode(path)
-				if err != nil {
-					return err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: content.B_inodo},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				return nil
-			}
-		}
 
+	folderInode := &Inode{
+		I_uid:   utils.LogedUserID,
+		I_gid:   utils.LogedUserGroupID,
+		I_size:  0,
+		I_atime: float32(time.Now().Unix()),
+		I_ctime: float32(time.Now().Unix()),
+		I_mtime: float32(time.Now().Unix()),
+		I_block: [15]int32{newBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_type:  [1]byte{'0'},
+		I_perm:  [3]byte{'6', '6', '4'},
+	}
+	err = folderInode.Serialize(path, int64(sb.S_inode_start+(newInodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return err
 	}
-	return nil
-}
 
-func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, parentsDir []string, destDir string) error {
-	inodo := &Inode{}
-	err := inodo.Deserialize(path, int64(sb.S_inode_start+inodeIndex*sb.S_inode_size))
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: newInodeIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: folderIndex},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	err = folderBlock.Serialize(path, int64(sb.S_block_start+(newBlockIndex*sb.S_block_size)))
 	if err != nil {
 		return err
 	}
+	return sb.AddEntryToFolder(path, folderIndex, destDir, newInodeIndex)
+}
+
+func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, parentsDir []string, destDir string) error {
 	if len(parentsDir) == 0 {
 		sb.createFolderInInode(path, inodeIndex, make([]string, 0), destDir, true)
 		return nil
@@ -240,11 +94,11 @@ func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, pa
 		return err
 	}
 
-	flag, neoInodoToVisit, err := existTheDirectory(inodo, nameDir, path, sb)
+	neoInodoToVisit, err := sb.FindEntryInFolder(path, inodeIndex, nameDir)
 	if err != nil {
 		return err
 	}
-	if flag { //si existe el primer dir
+	if neoInodoToVisit != -1 { //si existe el primer dir
 		sb.createFolderInInodeWithP(path, neoInodoToVisit, utils.RemoveElement(parentsDir, 0), destDir)
 	} else { //No existe el primero dir
 		sb.createFolderInInode(path, inodeIndex, make([]string, 0), nameDir, true)
@@ -253,820 +107,568 @@ func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, pa
 	return nil
 }
 
-// Asumiendo que hayan dirs q crear
-func existTheDirectory(inodo *Inode, nameDir string, path string, sb *SuperBlock) (bool, int32, error) {
-	for i, blockIndex := range inodo.I_block {
-		if blockIndex == -1 {
-			return false, 0, nil
+// Baja por parentsDir desde la carpeta inodeIndex. Devuelve -1 si alguna carpeta
+// del camino no existe.
+func (sb *SuperBlock) findFolder(diskPath string, inodeIndex int32, parentsDir []string) (int32, error) {
+	for _, parentDir := range parentsDir {
+		inode := &Inode{}
+		err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+		if err != nil {
+			return -1, err
 		}
-		if i >= 14 {
-			pointerBlock := &PointerBlock{}
-			err := pointerBlock.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return false, 0, err
-			}
-			for _, value := range pointerBlock.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &FolderBlock{}
-				err := block.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*value)))
-				if err != nil {
-					return false, 0, err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-					parentDirName := strings.Trim(nameDir, "\x00 ")
-					if strings.EqualFold(contentName, parentDirName) {
-						return true, content.B_inodo, nil
-					}
-				}
-			}
-
-		} else {
-			block := &FolderBlock{}
-			err := block.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return false, 0, err
-			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(nameDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					return true, content.B_inodo, nil
-				}
-			}
+		if inode.I_type[0] != '0' {
+			return -1, nil
+		}
+		inodeIndex, err = sb.FindEntryInFolder(diskPath, inodeIndex, parentDir)
+		if err != nil || inodeIndex == -1 {
+			return -1, err
 		}
 	}
-	return false, 0, nil
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return -1, err
+	}
+	if inode.I_type[0] != '0' {
+		return -1, nil
+	}
+	return inodeIndex, nil
 }
 
 func (sb *SuperBlock) CreateFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string, fileContent string, size int32, justSearchingAFile bool) error {
+	folderIndex, err := sb.findFolder(diskPath, inodeIndex, parentsDir)
+	if err != nil {
+		return err
+	}
+	if folderIndex == -1 {
+		if justSearchingAFile {
+			return nil
+		}
+		return errors.New("ruta invalida, asegurese que exita la ruta antes")
+	}
 	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 	if err != nil {
 		return err
 	}
-	if inode.I_type[0] == '1' {
-		return nil
+	existing, err := sb.FindEntryInFolder(diskPath, folderIndex, destDir)
+	if err != nil {
+		return err
 	}
-	var inodoPadre int32
-	for i, blockIndex := range inode.I_block {
-		numeroApuntadorIndirect := sb.S_blocks_count
-		if blockIndex == -1 {
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if i >= 14 {
-				inode.I_block[i] = sb.S_blocks_count
-
-				pointerBlock := &PointerBlock{
-					P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-				}
-				err = pointerBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
+	if existing != -1 {
+		return errors.New("ya existe un file con el mismo nombre")
+	}
+	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return errors.New("inaccesible por falta de permisos")
+	}
+	dataBlocks := len(utils.SplitStringIntoChunks(fileContent))
+	if dataBlocks > MaxInodeBlocks() {
+		return errors.New("el contenido excede la capacidad maxima de un archivo")
+	}
+	err = sb.checkSpaceForEntry(diskPath, inode, BlocksNeeded(dataBlocks))
+	if err != nil {
+		return err
+	}
+	newInodeIndex, err := sb.AllocateInode(diskPath)
+	if err != nil {
+		return err
+	}
+	fileInode := &Inode{
+		I_uid:   utils.LogedUserID,
+		I_gid:   utils.LogedUserGroupID,
+		I_size:  int32(len(fileContent)),
+		I_atime: float32(time.Now().Unix()),
+		I_ctime: float32(time.Now().Unix()),
+		I_mtime: float32(time.Now().Unix()),
+		I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_type:  [1]byte{'1'},
+		I_perm:  [3]byte{'6', '6', '4'},
+	}
+	err = sb.WriteFileBlocks(diskPath, fileInode, fileContent)
+	if err != nil {
+		return err
+	}
+	err = fileInode.Serialize(diskPath, int64(sb.S_inode_start+(newInodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return err
+	}
+	return sb.AddEntryToFolder(diskPath, folderIndex, destDir, newInodeIndex)
+}
 
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
+// Reparte el contenido en bloques de 64 bytes y los enlaza en orden en los
+// apuntadores directos e indirectos del inodo
+func (sb *SuperBlock) WriteFileBlocks(diskPath string, inode *Inode, fileContent string) error {
+	contentChunks := utils.SplitStringIntoChunks(fileContent)
+	if len(contentChunks) > MaxInodeBlocks() {
+		return errors.New("el contenido excede la capacidad maxima de un archivo")
+	}
+	for i, content := range contentChunks {
+		contentBlockIndex, err := sb.AllocateBlock(diskPath)
+		if err != nil {
+			return err
+		}
+		contentBlock := &FileBlock{
+			B_content: [64]byte{},
+		}
+		copy(contentBlock.B_content[:], content)
+		err = contentBlock.Serialize(diskPath, int64(sb.S_block_start+(contentBlockIndex*sb.S_block_size)))
+		if err != nil {
+			return err
+		}
+		err = sb.SetInodeBlock(diskPath, inode, i, contentBlockIndex)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-				//Bloque folder
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
+// Reemplaza el contenido de un inodo archivo. Los bloques anteriores se liberan
+// y se asignan de nuevo los que necesite el contenido nuevo, antes de liberar
+// nada se revisa que alcancen para no dejar el inodo apuntando a bloques libres.
+func (sb *SuperBlock) RewriteFile(diskPath string, inodeIndex int32, fileContent string) error {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '1' {
+		return errors.New("el path no corresponde a un archivo")
+	}
+	dataBlocks := len(utils.SplitStringIntoChunks(fileContent))
+	if dataBlocks > MaxInodeBlocks() {
+		return errors.New("el contenido excede la capacidad maxima de un archivo")
+	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	pointers, err := sb.GetInodePointerBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	available := int(sb.S_free_blocks_count) + len(blocks) + len(pointers)
+	if BlocksNeeded(dataBlocks) > available {
+		return fmt.Errorf("no hay bloques libres suficientes para el contenido (se necesitan %d, hay %d)", BlocksNeeded(dataBlocks), available)
+	}
+	err = sb.ReleaseInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	err = sb.WriteFileBlocks(diskPath, inode, fileContent)
+	if err != nil {
+		return err
+	}
+	inode.I_size = int32(len(fileContent))
+	inode.I_mtime = float32(time.Now().Unix())
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+}
 
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
+func (sb *SuperBlock) ContentFromFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
+	folderIndex, err := sb.findFolder(diskPath, inodeIndex, parentsDir)
+	if err != nil {
+		return "", err
+	}
+	if folderIndex == -1 {
+		return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
+	}
+	fileIndex, err := sb.FindEntryInFolder(diskPath, folderIndex, destDir)
+	if err != nil {
+		return "", err
+	}
+	if fileIndex == -1 {
+		return "", errors.New("se ha producido un error en reportFile")
+	}
+	return sb.ReadFileContent(diskPath, fileIndex)
+}
 
-				inode.Serialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				flag, err := sb.folderFromAuntadorIndirecto13(diskPath, inodeIndex, parentsDir, destDir, justSearchingAFile, numeroApuntadorIndirect, inodoPadre)
-				if err != nil {
-					return err
-				}
-				if flag {
-					return nil
-				}
-			} else {
-				inode.I_block[i] = sb.S_blocks_count
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
+func (sb *SuperBlock) ContentFromFileCat(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
+	folderIndex, err := sb.findFolder(diskPath, inodeIndex, parentsDir)
+	if err != nil {
+		return "", err
+	}
+	if folderIndex == -1 {
+		return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
+	}
+	fileIndex, err := sb.FindEntryInFolder(diskPath, folderIndex, destDir)
+	if err != nil {
+		return "", err
+	}
+	if fileIndex == -1 {
+		return "", errors.New("se ha producido un error en reportFile")
+	}
+	inodoFile := &Inode{}
+	err = inodoFile.Deserialize(diskPath, int64(sb.S_inode_start+(fileIndex*sb.S_inode_size)))
+	if err != nil {
+		return "", err
+	}
+	outcome, err := inodoFile.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
+	if err != nil {
+		return "", err
+	}
+	if !outcome {
+		return "inaccesible por falta de permisos", nil
+	}
+	return sb.ReadFileContent(diskPath, fileIndex)
+}
 
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-				inode.Serialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				return sb.CreateFile(diskPath, inodeIndex, parentsDir, destDir, fileContent, size, justSearchingAFile)
-			}
+// Devuelve el inodo de la entrada con ese nombre dentro de la carpeta, o -1 si no existe
+func (sb *SuperBlock) FindEntryInFolder(diskPath string, folderIndex int32, name string) (int32, error) {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
+	if err != nil {
+		return -1, err
+	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return -1, err
+	}
+	for _, blockIndex := range blocks {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return -1, err
 		}
-
-		if i >= 14 {
-			pointerBlock := &PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return err
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			content := block.B_content[indexContent]
+			if content.B_inodo == -1 {
+				continue
 			}
-			for _, neoBlockIndex := range pointerBlock.P_pointers {
-				if neoBlockIndex == -1 {
-					continue
-				}
-				block := &FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoBlockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if len(parentsDir) != 0 {
-						if content.B_inodo == -1 {
-							continue
-						}
-						parentDir, err := utils.First(parentsDir)
-						if err != nil {
-							return err
-						}
-						contentName := strings.Trim(string(content.B_name[:]), "\x00")
-						parentDirName := strings.Trim(parentDir, "\x00")
-						if strings.EqualFold(contentName, parentDirName) {
-							err := sb.CreateFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, fileContent, size, justSearchingAFile)
-							if err != nil {
-								return err
-							}
-							return nil
-						}
-					} else {
-						contentName := strings.Trim(string(content.B_name[:]), "\x00")
-						destinationName := strings.Trim(destDir, "\x00")
-						if strings.EqualFold(contentName, destinationName) {
-							return errors.New("ya existe un file con el mismo nombre")
-						}
-						if content.B_inodo != -1 {
-							tempContent := block.B_content[1]
-							inodoPadre = tempContent.B_inodo
-							continue
-						}
-						copy(content.B_name[:], destDir)
-						content.B_inodo = sb.S_inodes_count
-						block.B_content[indexContent] = content
-						err = block.Serialize(diskPath, int64(sb.S_block_start+(neoBlockIndex*sb.S_block_size)))
-						if err != nil {
-							return err
-						}
-						folderInode := &Inode{
-							I_uid:   utils.LogedUserID,
-							I_gid:   utils.LogedUserGroupID,
-							I_size:  int32(len(fileContent)),
-							I_atime: float32(time.Now().Unix()),
-							I_ctime: float32(time.Now().Unix()),
-							I_mtime: float32(time.Now().Unix()),
-							I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-							I_type:  [1]byte{'1'},
-							I_perm:  [3]byte{'6', '6', '4'},
-						}
-						contentChunks := utils.SplitStringIntoChunks(fileContent)
-						// for i := range contentChunks {
-						// 	folderInode.I_block[i] = sb.S_blocks_count + int32(i)
-
-						// }
-						offsetInodo := sb.S_first_ino
-						err = folderInode.Serialize(diskPath, int64(offsetInodo))
-						if err != nil {
-							return err
-						}
-						err = sb.UpdateBitmapInode(diskPath)
-						if err != nil {
-							return err
-						}
-
-						sb.S_inodes_count++
-						sb.S_free_inodes_count--
-						sb.S_first_ino += sb.S_inode_size
-						// Flag de repetir el llenado
-						flagToCreateNeoBlockPointer := true
-						tempCont := 14
-						// Index del bloque pointer
-						var indexBlockPointer int32
-						tempBlockPointer := &PointerBlock{}
-						for i, content := range contentChunks {
-							if i >= 14 { //Aputnadores indirectos
-								if flagToCreateNeoBlockPointer {
-									for id, value := range folderInode.I_block {
-										if value == -1 {
-											folderInode.I_block[id] = sb.S_blocks_count
-											break
-										}
-									}
-									indexBlockPointer = folderInode.I_block[tempCont]
-									pointerBlock := &PointerBlock{
-										P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-									}
-									tempBlockPointer = pointerBlock
-									flagToCreateNeoBlockPointer = false
-									err = sb.UpdateBitmapBlock(diskPath)
-									if err != nil {
-										return err
-									}
-									sb.S_blocks_count++
-									sb.S_free_blocks_count--
-									sb.S_first_blo += sb.S_block_size
-								}
-								flag, err := stuffTheBlock(sb, tempBlockPointer, content, diskPath)
-								if err != nil {
-									return err
-								}
-								err = tempBlockPointer.Serialize(diskPath, int64(sb.S_block_start+(indexBlockPointer*sb.S_block_size)))
-								if err != nil {
-									return err
-								}
-								if !flag {
-									tempCont++
-									flagToCreateNeoBlockPointer = true
-									indexBlockPointer = folderInode.I_block[tempCont]
-								}
-							} else {
-								folderInode.I_block[i] = sb.S_blocks_count
-								contentBlock := &FileBlock{
-									B_content: [64]byte{},
-								}
-								copy(contentBlock.B_content[:], content)
-								err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-								if err != nil {
-									return err
-								}
-
-								err = sb.UpdateBitmapBlock(diskPath)
-								if err != nil {
-									return err
-								}
-								sb.S_blocks_count++
-								sb.S_free_blocks_count--
-								sb.S_first_blo += sb.S_block_size
-							}
-						}
-						err = folderInode.Serialize(diskPath, int64(offsetInodo))
-						if err != nil {
-							return err
-						}
-						return nil
-					}
-				}
+			contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
+			if strings.EqualFold(contentName, strings.Trim(name, "\x00 ")) {
+				return content.B_inodo, nil
 			}
-
 		}
+	}
+	return -1, nil
+}
+
+// Agrega una entrada a la carpeta usando el primer espacio libre. Si todos los
+// bloques estan llenos se crea un bloque carpeta nuevo (directo o indirecto).
+func (sb *SuperBlock) AddEntryToFolder(diskPath string, folderIndex int32, name string, entryInode int32) error {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
+	if err != nil {
+		return err
+	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	inodoPadre := folderIndex
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
 			return err
 		}
+		inodoPadre = block.B_content[1].B_inodo
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.CreateFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, fileContent, size, justSearchingAFile)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					return errors.New("ya existe un file con el mismo nombre")
-				}
-				outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
-				if err != nil {
-					return err
-				}
-				if !outcome {
-					return errors.New("inaccesible por falta de permisos")
-				}
-				if content.B_inodo != -1 {
-					tempContent := block.B_content[1]
-					inodoPadre = tempContent.B_inodo
-					continue
-				}
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-				block.B_content[indexContent] = content
-				err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  int32(len(fileContent)),
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'1'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-				contentChunks := utils.SplitStringIntoChunks(fileContent)
-				// for i := range contentChunks {
-				// 	folderInode.I_block[i] = sb.S_blocks_count + int32(i)
-
-				// }
-				offsetInodo := sb.S_first_ino
-				err = folderInode.Serialize(diskPath, int64(offsetInodo))
-				if err != nil {
-					return err
-				}
-				err = sb.UpdateBitmapInode(diskPath)
-				if err != nil {
-					return err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-				// Flag de repetir el llenado
-				flagToCreateNeoBlockPointer := true
-				tempCont := 14
-				// Index del bloque pointer
-				var indexBlockPointer int32
-				tempBlockPointer := &PointerBlock{}
-				for i, content := range contentChunks {
-					if i >= 14 { //Aputnadores indirectos
-						if flagToCreateNeoBlockPointer {
-							for id, value := range folderInode.I_block {
-								if value == -1 {
-									folderInode.I_block[id] = sb.S_blocks_count
-									break
-								}
-							}
-							indexBlockPointer = folderInode.I_block[tempCont]
-							pointerBlock := &PointerBlock{
-								P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-							}
-							tempBlockPointer = pointerBlock
-							flagToCreateNeoBlockPointer = false
-							err = sb.UpdateBitmapBlock(diskPath)
-							if err != nil {
-								return err
-							}
-							sb.S_blocks_count++
-							sb.S_free_blocks_count--
-							sb.S_first_blo += sb.S_block_size
-						}
-						flag, err := stuffTheBlock(sb, tempBlockPointer, content, diskPath)
-						if err != nil {
-							return err
-						}
-						err = tempBlockPointer.Serialize(diskPath, int64(sb.S_block_start+(indexBlockPointer*sb.S_block_size)))
-						if err != nil {
-							return err
-						}
-						if !flag {
-							tempCont++
-							flagToCreateNeoBlockPointer = true
-							indexBlockPointer = folderInode.I_block[tempCont]
-						}
-					} else {
-						folderInode.I_block[i] = sb.S_blocks_count
-						contentBlock := &FileBlock{
-							B_content: [64]byte{},
-						}
-						copy(contentBlock.B_content[:], content)
-						err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-						if err != nil {
-							return err
-						}
-
-						err = sb.UpdateBitmapBlock(diskPath)
-						if err != nil {
-							return err
-						}
-						sb.S_blocks_count++
-						sb.S_free_blocks_count--
-						sb.S_first_blo += sb.S_block_size
-					}
-				}
-				err = folderInode.Serialize(diskPath, int64(offsetInodo))
-				if err != nil {
-					return err
-				}
-				return nil
+			if block.B_content[indexContent].B_inodo != -1 {
+				continue
 			}
+			block.B_content[indexContent] = FolderContent{B_inodo: entryInode}
+			copy(block.B_content[indexContent].B_name[:], name)
+			return block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		}
 	}
-	return nil
+
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: folderIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
+			{B_inodo: entryInode},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	copy(folderBlock.B_content[2].B_name[:], name)
+	newBlockIndex, err := sb.allocateFolderBlockSlot(diskPath, inode, len(blocks))
+	if err != nil {
+		return err
+	}
+	err = folderBlock.Serialize(diskPath, int64(sb.S_block_start+(newBlockIndex*sb.S_block_size)))
+	if err != nil {
+		return err
+	}
+	inode.I_mtime = float32(time.Now().Unix())
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 }
 
-func stuffTheBlock(sb *SuperBlock, pointerBlock *PointerBlock, content string, diskPath string) (bool, error) {
-	for i, indexInode := range pointerBlock.P_pointers {
+// Reserva un bloque y lo enlaza como el bloque logico numero position del inodo
+func (sb *SuperBlock) allocateFolderBlockSlot(diskPath string, inode *Inode, position int) (int32, error) {
+	if position >= MaxInodeBlocks() {
+		return -1, errors.New("la carpeta no tiene espacio para mas entradas")
+	}
+	newBlockIndex, err := sb.AllocateBlock(diskPath)
+	if err != nil {
+		return -1, err
+	}
+	err = sb.SetInodeBlock(diskPath, inode, position, newBlockIndex)
+	if err != nil {
+		return -1, err
+	}
+	return newBlockIndex, nil
+}
 
-		if indexInode != -1 {
-			continue
-		}
-		pointerBlock.P_pointers[i] = sb.S_blocks_count
-		contentBlock := &FileBlock{
-			B_content: [64]byte{},
-		}
-		copy(contentBlock.B_content[:], content)
-		err := contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
+// Revisa antes de reservar nada que haya un inodo libre y bloques para la
+// entrada nueva, mas los que necesite la carpeta si ya no tiene espacios libres.
+// Asi un error no deja inodos o bloques marcados sin usar.
+func (sb *SuperBlock) checkSpaceForEntry(diskPath string, folder *Inode, blocks int) error {
+	if sb.S_free_inodes_count < 1 {
+		return errors.New("no hay inodos libres en la particion")
+	}
+	folderBlocks, err := sb.GetInodeBlocks(diskPath, folder)
+	if err != nil {
+		return err
+	}
+	full := true
+	for _, blockIndex := range folderBlocks {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
-			return false, err
+			return err
 		}
-
-		err = sb.UpdateBitmapBlock(diskPath)
-		if err != nil {
-			return false, err
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			if block.B_content[indexContent].B_inodo == -1 {
+				full = false
+			}
 		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		if i == len(pointerBlock.P_pointers)-1 {
-			return false, nil
-		} else {
-			return true, nil
+	}
+	if full {
+		if len(folderBlocks) >= MaxInodeBlocks() {
+			return errors.New("la carpeta no tiene espacio para mas entradas")
 		}
+		blocks += BlocksNeeded(len(folderBlocks)+1) - BlocksNeeded(len(folderBlocks))
+	}
+	if blocks > int(sb.S_free_blocks_count) {
+		return fmt.Errorf("no hay bloques libres suficientes (se necesitan %d, hay %d)", blocks, sb.S_free_blocks_count)
 	}
-	return false, errors.New("no entro al forr de llenado por apuntadores indirectos")
+	return nil
 }
 
-func (sb *SuperBlock) ContentFromFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
+// Quita la entrada de la carpeta sin liberar el inodo al que apunta
+func (sb *SuperBlock) RemoveEntryFromFolder(diskPath string, folderIndex int32, name string) error {
 	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 	if err != nil {
-		return "", err
+		return err
 	}
-	if inode.I_type[0] == '1' {
-		return "", errors.New("se entro a un Inodo tipo file en reportFile")
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
 	}
-	for _, blockIndex := range inode.I_block {
-
-		if blockIndex == -1 {
-			return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
-		}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
-			return "", err
+			return err
 		}
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
 			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return "", err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					content, err := sb.ContentFromFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
-					if err != nil {
-						return "", err
-					}
-					return content, nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					// Son iguales
-					inodoFile := &Inode{}
-					inodoFile.Deserialize(diskPath, int64(sb.S_inode_start+(content.B_inodo*sb.S_inode_size)))
-					var content string
-					for iTe, value := range inodoFile.I_block {
-						if value == -1 {
-							continue
-						}
-						if iTe >= 14 {
-							pointerBlock := &PointerBlock{}
-							err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							if err != nil {
-								return "", err
-							}
-							for _, indexContentBlock := range pointerBlock.P_pointers {
-								if indexContentBlock == -1 {
-									continue
-								}
-								blockContentFile := &FileBlock{}
-								blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*indexContentBlock)))
-								contentBlock := string(blockContentFile.B_content[:])
-								contentBlock = strings.TrimRight(contentBlock, "\x00")
-								content += contentBlock
-							}
-						} else {
-							blockContentFile := &FileBlock{}
-							blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							contentBlock := string(blockContentFile.B_content[:])
-							contentBlock = strings.TrimRight(contentBlock, "\x00")
-							content += contentBlock
-						}
-					}
-					return content, nil
-				}
-				if content.B_inodo != -1 {
-					continue
-				}
+			if content.B_inodo == -1 {
+				continue
+			}
+			contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
+			if !strings.EqualFold(contentName, strings.Trim(name, "\x00 ")) {
+				continue
+			}
+			block.B_content[indexContent] = FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}
+			err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+			if err != nil {
+				return err
 			}
+			inode.I_mtime = float32(time.Now().Unix())
+			return inode.Serialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 		}
 	}
-	return "", errors.New("se ha producido un error en reportFile")
+	return errors.New("no existe la entrada en la carpeta")
 }
 
-func (sb *SuperBlock) ContentFromFileCat(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
+// Cambia el nombre de una entrada de la carpeta sin tocar el inodo al que apunta
+func (sb *SuperBlock) RenameEntryInFolder(diskPath string, folderIndex int32, name, newName string) error {
 	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 	if err != nil {
-		return "", err
+		return err
 	}
-	if inode.I_type[0] == '1' {
-		return "", errors.New("se entro a un Inodo tipo file en reportFile")
-	}
-	// outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
-	// if err != nil {
-	// 	return "", err
-	// }
-	// if !outcome {
-	// 	return "", errors.New("inaccesible por falta de permisos")
-	// }
-	for _, blockIndex := range inode.I_block {
-
-		if blockIndex == -1 {
-			return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
-		}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
-			return "", err
+			return err
 		}
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
 			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return "", err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					content, err := sb.ContentFromFileCat(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
-					if err != nil {
-						return "", err
-					}
-					return content, nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					// Son iguales
-					inodoFile := &Inode{}
-					inodoFile.Deserialize(diskPath, int64(sb.S_inode_start+(content.B_inodo*sb.S_inode_size)))
-					outcome, err := inodoFile.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
-					if err != nil {
-						return "", err
-					}
-					if !outcome {
-						return "inaccesible por falta de permisos", nil
-					}
-					var content string
-					for iTe, value := range inodoFile.I_block {
-						if value == -1 {
-							continue
-						}
-						if iTe >= 14 {
-							pointerBlock := &PointerBlock{}
-							err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							if err != nil {
-								return "", err
-							}
-							for _, indexContentBlock := range pointerBlock.P_pointers {
-								if indexContentBlock == -1 {
-									continue
-								}
-								blockContentFile := &FileBlock{}
-								blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*indexContentBlock)))
-								contentBlock := string(blockContentFile.B_content[:])
-								contentBlock = strings.TrimRight(contentBlock, "\x00")
-								content += contentBlock
-							}
-						} else {
-							blockContentFile := &FileBlock{}
-							blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							contentBlock := string(blockContentFile.B_content[:])
-							contentBlock = strings.TrimRight(contentBlock, "\x00")
-							content += contentBlock
-						}
-					}
-					return content, nil
-				}
-				if content.B_inodo != -1 {
-					continue
-				}
+			if content.B_inodo == -1 {
+				continue
+			}
+			contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
+			if !strings.EqualFold(contentName, strings.Trim(name, "\x00 ")) {
+				continue
+			}
+			block.B_content[indexContent].B_name = [12]byte{}
+			copy(block.B_content[indexContent].B_name[:], newName)
+			err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+			if err != nil {
+				return err
 			}
+			inode.I_mtime = float32(time.Now().Unix())
+			return inode.Serialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 		}
 	}
-	return "", errors.New("se ha producido un error en reportFile")
+	return errors.New("no existe la entrada en la carpeta")
 }
 
-func (sb *SuperBlock) folderFromAuntadorIndirecto13(diskPath string, inodeIndex int32, parentsDir []string, destDir string, justSearchingAFile bool, numApuntadorIndirecto int32, inodoPadre int32) (bool, error) {
-	pointerBlock := &PointerBlock{}
-	err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*numApuntadorIndirecto)))
+// Cambia la entrada '..' de todos los bloques de la carpeta
+func (sb *SuperBlock) SetFolderParent(diskPath string, folderIndex int32, parentIndex int32) error {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(folderIndex*sb.S_inode_size)))
 	if err != nil {
-		return false, err
+		return err
 	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range blocks {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return err
+		}
+		block.B_content[1].B_inodo = parentIndex
+		err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	for i, blockIndex := range pointerBlock.P_pointers {
-		if blockIndex == -1 {
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return false, errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-
-			pointerBlock.P_pointers[i] = sb.S_blocks_count
-			folderBlock := &FolderBlock{
-				B_content: [4]FolderContent{
-					{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-					{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-					{B_name: [12]byte{'-'}, B_inodo: -1},
-					{B_name: [12]byte{'-'}, B_inodo: -1},
-				},
-			}
-			err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-			if err != nil {
-				return false, err
-			}
+// Lee el contenido completo de un inodo archivo
+func (sb *SuperBlock) ReadFileContent(diskPath string, inodeIndex int32) (string, error) {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return "", err
+	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return "", err
+	}
+	var content string
+	for _, blockIndex := range blocks {
+		fileBlock := &FileBlock{}
+		err := fileBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return "", err
+		}
+		content += strings.TrimRight(string(fileBlock.B_content[:]), "\x00")
+	}
+	return content, nil
+}
 
-			err = sb.UpdateBitmapBlock(diskPath)
-			if err != nil {
-				return false, err
-			}
-			sb.S_blocks_count++
-			sb.S_free_blocks_count--
-			sb.S_first_blo += sb.S_block_size
+// Indica si el inodo index esta dentro del subarbol de la carpeta ancestor,
+// subiendo por las entradas '..' hasta llegar a la raiz
+func (sb *SuperBlock) IsInSubtree(diskPath string, ancestor int32, index int32) (bool, error) {
+	visited := make(map[int32]bool)
+	for !visited[index] {
+		if index == ancestor {
+			return true, nil
+		}
+		visited[index] = true
+		inode := &Inode{}
+		err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(index*sb.S_inode_size)))
+		if err != nil {
+			return false, err
+		}
+		if inode.I_type[0] != '0' || inode.I_block[0] == -1 {
+			return false, nil
+		}
+		block := &FolderBlock{}
+		err = block.Deserialize(diskPath, int64(sb.S_block_start+(inode.I_block[0]*sb.S_block_size)))
+		if err != nil {
+			return false, err
+		}
+		index = block.B_content[1].B_inodo
+	}
+	return false, nil
+}
 
-			err = pointerBlock.Serialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*numApuntadorIndirecto)))
-			if err != nil {
-				return false, err
+// Inodos de las entradas de la carpeta, sin contar '.' ni '..'
+func (sb *SuperBlock) GetFolderChildren(diskPath string, inode *Inode) ([]int32, error) {
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]int32, 0)
+	for _, blockIndex := range blocks {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return nil, err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			if block.B_content[indexContent].B_inodo != -1 {
+				children = append(children, block.B_content[indexContent].B_inodo)
 			}
-
-			return sb.folderFromAuntadorIndirecto13(diskPath, inodeIndex, parentsDir, destDir, justSearchingAFile, numApuntadorIndirecto, inodoPadre)
 		}
+	}
+	return children, nil
+}
 
-		// Si es la iteracion 13,
+// Recorre el arbol en preorden desde la raiz y llama fn con la ruta de cada inodo
+func (sb *SuperBlock) WalkTree(diskPath string, fn func(path string, inodeIndex int32, inode *Inode) error) error {
+	return sb.walkTree(diskPath, "/", 0, fn, make(map[int32]bool))
+}
+
+func (sb *SuperBlock) walkTree(diskPath, path string, inodeIndex int32, fn func(string, int32, *Inode) error, visited map[int32]bool) error {
+	if visited[inodeIndex] {
+		return fmt.Errorf("el inodo %d aparece dos veces en el arbol", inodeIndex)
+	}
+	visited[inodeIndex] = true
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return err
+	}
+	err = fn(path, inodeIndex, inode)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '0' {
+		return nil
+	}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
-			return false, err
+			return err
 		}
-
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
 			content := block.B_content[indexContent]
-
-			if len(parentsDir) != 0 {
-
-				if content.B_inodo == -1 {
-					continue
-				}
-
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return false, err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.createFolderInInode(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, justSearchingAFile)
-					if err != nil {
-						return false, err
-					}
-					return true, nil
-				}
-			} else {
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					return false, errors.New("ya existe un directorio con el mismo nombre")
-				}
-
-				if content.B_inodo != -1 {
-					continue
-				}
-
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-
-				block.B_content[indexContent] = content
-
-				err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return false, err
-				}
-
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'0'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-
-				err = folderInode.Serialize(diskPath, int64(sb.S_first_ino))
-				if err != nil {
-					return false, err
-				}
-
-				err = sb.UpdateBitmapInode(diskPath)
-				if err != nil {
-					return false, err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: content.B_inodo},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return false, err
-				}
-
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return false, err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				err = pointerBlock.Serialize(diskPath, int64(sb.S_inode_start+(numApuntadorIndirecto*sb.S_inode_size)))
-				if err != nil {
-					return false, err
-				}
-				return true, nil
+			if content.B_inodo == -1 {
+				continue
+			}
+			name := strings.Trim(string(content.B_name[:]), "\x00 ")
+			childPath := strings.TrimSuffix(path, "/") + "/" + name
+			err = sb.walkTree(diskPath, childPath, content.B_inodo, fn, visited)
+			if err != nil {
+				return err
 			}
 		}
 	}
-	return false, nil
+	return nil
 }
@@ -0,0 +1,96 @@
+package structures
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Hueco libre del disco (o de la extendida) medido en bytes
+type FreeSpace struct {
+	Start int32
+	Size  int32
+}
+
+// Huecos libres entre el final del MBR y el final del disco, ordenados por posicion
+func (mbr *MBR) GetFreeSpaces() []FreeSpace {
+	used := make([]FreeSpace, 0, len(mbr.Mbr_partitions))
+	for _, partition := range mbr.Mbr_partitions {
+		if partition.Part_start == -1 || partition.Part_size <= 0 {
+			continue
+		}
+		used = append(used, FreeSpace{Start: partition.Part_start, Size: partition.Part_size})
+	}
+	return freeSpacesBetween(int32(binary.Size(mbr)), mbr.Mbr_size, used)
+}
+
+// Huecos libres dentro de la extendida. La cabecera vacia no ocupa espacio
+// porque se puede reutilizar para una logica que empiece en el mismo lugar.
+func (mbr *MBR) GetExtendedFreeSpaces(path string) ([]FreeSpace, error) {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return nil, err
+	}
+	ebrs, err := mbr.GetEBRs(path)
+	if err != nil {
+		return nil, err
+	}
+	used := make([]FreeSpace, 0, len(ebrs))
+	for _, ebr := range ebrs {
+		if ebr.IsEmpty() {
+			continue
+		}
+		used = append(used, FreeSpace{Start: ebr.Ebr_start, Size: ebr.Ebr_size})
+	}
+	return freeSpacesBetween(extended.Part_start, extended.Part_start+extended.Part_size, used), nil
+}
+
+func freeSpacesBetween(start, end int32, used []FreeSpace) []FreeSpace {
+	sort.Slice(used, func(i, j int) bool { return used[i].Start < used[j].Start })
+	spaces := make([]FreeSpace, 0)
+	cursor := start
+	for _, space := range used {
+		if space.Start > cursor {
+			spaces = append(spaces, FreeSpace{Start: cursor, Size: space.Start - cursor})
+		}
+		if space.Start+space.Size > cursor {
+			cursor = space.Start + space.Size
+		}
+	}
+	if end > cursor {
+		spaces = append(spaces, FreeSpace{Start: cursor, Size: end - cursor})
+	}
+	return spaces
+}
+
+/*
+Elige el inicio de un hueco donde quepan size bytes:
+
+	F: el primer hueco que alcance
+	B: el hueco mas pequeno que alcance
+	W: el hueco mas grande
+*/
+func ChooseFreeSpace(spaces []FreeSpace, size int32, fit byte) (int32, error) {
+	chosen := -1
+	for i, space := range spaces {
+		if space.Size < size {
+			continue
+		}
+		if chosen == -1 {
+			chosen = i
+			if fit == 'F' {
+				break
+			}
+			continue
+		}
+		if fit == 'B' && space.Size < spaces[chosen].Size {
+			chosen = i
+		} else if fit == 'W' && space.Size > spaces[chosen].Size {
+			chosen = i
+		}
+	}
+	if chosen == -1 {
+		return -1, errors.New("no hay un espacio libre contiguo suficiente para la particion")
+	}
+	return spaces[chosen].Start, nil
+}
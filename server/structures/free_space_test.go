@@ -0,0 +1,71 @@
+package structures
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFreeSpacesBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		used []FreeSpace
+		want []FreeSpace
+	}{
+		{"disco vacio", nil, []FreeSpace{{0, 100}}},
+		{"disco lleno", []FreeSpace{{0, 100}}, []FreeSpace{}},
+		{"huecos en los extremos", []FreeSpace{{20, 30}}, []FreeSpace{{0, 20}, {50, 50}}},
+		{"desordenadas", []FreeSpace{{60, 10}, {10, 10}}, []FreeSpace{{0, 10}, {20, 40}, {70, 30}}},
+		{"contiguas", []FreeSpace{{0, 10}, {10, 10}}, []FreeSpace{{20, 80}}},
+		{"superpuestas", []FreeSpace{{10, 30}, {20, 10}, {35, 15}}, []FreeSpace{{0, 10}, {50, 50}}},
+		{"una dentro de otra", []FreeSpace{{10, 50}, {20, 5}}, []FreeSpace{{0, 10}, {60, 40}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := freeSpacesBetween(0, 100, slices.Clone(test.used))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("freeSpacesBetween = %v, se esperaba %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChooseFreeSpace(t *testing.T) {
+	spaces := []FreeSpace{{0, 30}, {40, 10}, {60, 50}, {120, 20}}
+	tests := []struct {
+		name string
+		size int32
+		fit  byte
+		want int32
+	}{
+		{"primero", 15, 'F', 0},
+		{"primero que alcanza", 35, 'F', 60},
+		{"mejor", 15, 'B', 120},
+		{"mejor exacto", 10, 'B', 40},
+		{"peor", 5, 'W', 60},
+		{"peor con un solo hueco que alcanza", 40, 'W', 60},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ChooseFreeSpace(spaces, test.size, test.fit)
+			if err != nil {
+				t.Fatalf("ChooseFreeSpace devolvio error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("ChooseFreeSpace(%d, %c) = %d, se esperaba %d", test.size, test.fit, got, test.want)
+			}
+		})
+	}
+}
+
+func TestChooseFreeSpaceNoFit(t *testing.T) {
+	for _, fit := range []byte{'F', 'B', 'W'} {
+		_, err := ChooseFreeSpace([]FreeSpace{{0, 30}, {40, 10}}, 31, fit)
+		if err == nil {
+			t.Errorf("ChooseFreeSpace con ajuste %c deberia fallar si ningun hueco alcanza", fit)
+		}
+	}
+	_, err := ChooseFreeSpace(nil, 1, 'F')
+	if err == nil {
+		t.Errorf("ChooseFreeSpace deberia fallar sin huecos")
+	}
+}
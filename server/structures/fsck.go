@@ -0,0 +1,223 @@
+package structures
+
+import (
+	"fmt"
+)
+
+// Estado que se va llenando al recorrer el arbol desde la raiz
+type fsckState struct {
+	totalInodes int32
+	totalBlocks int32
+	inodes      map[int32]bool
+	blocks      map[int32]int32 // bloque -> inodo que lo referencia
+	problems    []string
+}
+
+func (state *fsckState) report(format string, args ...any) {
+	state.problems = append(state.problems, fmt.Sprintf(format, args...))
+}
+
+/*
+Revisa que el superbloque, los bitmaps y las referencias que se alcanzan desde
+el inodo 0 coincidan. Con repair se corrigen las entradas '..', los bitmaps y
+los contadores; los bloques referenciados dos veces solo se reportan.
+El total de inodos y bloques se toma de la distribucion de la particion y no
+de los contadores, porque esos tambien pueden estar mal.
+*/
+func (sb *SuperBlock) CheckConsistency(path string, repair bool) ([]string, error) {
+	state := &fsckState{
+		totalInodes: sb.S_bm_block_start - sb.S_bm_inode_start,
+		totalBlocks: sb.S_inode_start - sb.S_bm_block_start,
+		inodes:      make(map[int32]bool),
+		blocks:      make(map[int32]int32),
+	}
+	err := sb.checkInode(path, 0, 0, state, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmapInode, err := readBitmap(path, sb.S_bm_inode_start, state.totalInodes)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range bitmapInode {
+		index := int32(i)
+		used := value == '1'
+		if used && !state.inodes[index] {
+			state.report("inodo %d huerfano: esta ocupado en el bitmap pero no se alcanza desde la raiz", index)
+		} else if !used && state.inodes[index] {
+			state.report("inodo %d en uso pero marcado libre en el bitmap", index)
+		} else {
+			continue
+		}
+		if repair {
+			err = sb.UpdateBitmapInode(path, index, state.inodes[index])
+			if err != nil {
+				return nil, err
+			}
+			bitmapInode[i] = '0'
+			if state.inodes[index] {
+				bitmapInode[i] = '1'
+			}
+		}
+	}
+
+	bitmapBlock, err := readBitmap(path, sb.S_bm_block_start, state.totalBlocks)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range bitmapBlock {
+		index := int32(i)
+		_, referenced := state.blocks[index]
+		used := value == 'X'
+		if used && !referenced {
+			state.report("bloque %d ocupado en el bitmap pero ningun inodo lo referencia", index)
+		} else if !used && referenced {
+			state.report("bloque %d en uso por el inodo %d pero marcado libre en el bitmap", index, state.blocks[index])
+		} else {
+			continue
+		}
+		if repair {
+			err = sb.UpdateBitmapBlock(path, index, referenced)
+			if err != nil {
+				return nil, err
+			}
+			bitmapBlock[i] = 'O'
+			if referenced {
+				bitmapBlock[i] = 'X'
+			}
+		}
+	}
+
+	usedInodes := countUsed(bitmapInode, '1')
+	usedBlocks := countUsed(bitmapBlock, 'X')
+	if sb.S_inodes_count != usedInodes || sb.S_free_inodes_count != state.totalInodes-usedInodes {
+		state.report("el superbloque indica %d inodos usados y %d libres pero el bitmap tiene %d y %d", sb.S_inodes_count, sb.S_free_inodes_count, usedInodes, state.totalInodes-usedInodes)
+	}
+	if sb.S_blocks_count != usedBlocks || sb.S_free_blocks_count != state.totalBlocks-usedBlocks {
+		state.report("el superbloque indica %d bloques usados y %d libres pero el bitmap tiene %d y %d", sb.S_blocks_count, sb.S_free_blocks_count, usedBlocks, state.totalBlocks-usedBlocks)
+	}
+	if repair {
+		sb.S_inodes_count = usedInodes
+		sb.S_free_inodes_count = state.totalInodes - usedInodes
+		sb.S_blocks_count = usedBlocks
+		sb.S_free_blocks_count = state.totalBlocks - usedBlocks
+		sb.S_first_ino = sb.S_inode_start + firstFree(bitmapInode, '0')*sb.S_inode_size
+		sb.S_first_blo = sb.S_block_start + firstFree(bitmapBlock, 'O')*sb.S_block_size
+	}
+	return state.problems, nil
+}
+
+func (sb *SuperBlock) checkInode(path string, index, parent int32, state *fsckState, repair bool) error {
+	if index < 0 || index >= state.totalInodes {
+		state.report("la carpeta %d apunta al inodo %d que esta fuera de rango", parent, index)
+		return nil
+	}
+	if state.inodes[index] {
+		state.report("el inodo %d esta referenciado mas de una vez", index)
+		return nil
+	}
+	state.inodes[index] = true
+
+	inode := &Inode{}
+	err := inode.Deserialize(path, int64(sb.S_inode_start+(index*sb.S_inode_size)))
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '0' && inode.I_type[0] != '1' {
+		state.report("el inodo %d tiene un tipo invalido", index)
+		return nil
+	}
+	blocks, pointers, ok := sb.checkInodeBlocks(path, index, inode, state)
+	if !ok {
+		return nil
+	}
+	for _, blockIndex := range append(blocks, pointers...) {
+		if owner, exists := state.blocks[blockIndex]; exists {
+			state.report("el bloque %d esta referenciado por los inodos %d y %d", blockIndex, owner, index)
+			continue
+		}
+		state.blocks[blockIndex] = index
+	}
+	if inode.I_type[0] != '0' {
+		return nil
+	}
+
+	for _, blockIndex := range blocks {
+		block := &FolderBlock{}
+		offset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
+		err := block.Deserialize(path, offset)
+		if err != nil {
+			return err
+		}
+		if block.B_content[0].B_inodo != index || block.B_content[1].B_inodo != parent {
+			state.report("el bloque carpeta %d del inodo %d tiene '.' = %d y '..' = %d, se esperaba %d y %d", blockIndex, index, block.B_content[0].B_inodo, block.B_content[1].B_inodo, index, parent)
+			if repair {
+				block.B_content[0].B_inodo = index
+				block.B_content[1].B_inodo = parent
+				err = block.Serialize(path, offset)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			child := block.B_content[indexContent].B_inodo
+			if child == -1 {
+				continue
+			}
+			err = sb.checkInode(path, child, index, state, repair)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Valida que todos los apuntadores del inodo esten dentro de la particion antes
+// de recorrerlos
+func (sb *SuperBlock) checkInodeBlocks(path string, index int32, inode *Inode, state *fsckState) ([]int32, []int32, bool) {
+	for _, blockIndex := range inode.I_block {
+		if blockIndex < -1 || blockIndex >= state.totalBlocks {
+			state.report("el inodo %d apunta al bloque %d que esta fuera de rango", index, blockIndex)
+			return nil, nil, false
+		}
+	}
+	pointers, err := sb.GetInodePointerBlocks(path, inode)
+	if err == nil {
+		for _, pointerIndex := range pointers {
+			pointerBlock := &PointerBlock{}
+			err = pointerBlock.Deserialize(path, int64(sb.S_block_start+(pointerIndex*sb.S_block_size)))
+			if err != nil {
+				break
+			}
+			for _, value := range pointerBlock.P_pointers {
+				if value < -1 || value >= state.totalBlocks {
+					state.report("el bloque de apuntadores %d del inodo %d apunta al bloque %d que esta fuera de rango", pointerIndex, index, value)
+					return nil, nil, false
+				}
+			}
+		}
+	}
+	if err != nil {
+		state.report("no se pudieron leer los bloques del inodo %d: %v", index, err)
+		return nil, nil, false
+	}
+	blocks, err := sb.GetInodeBlocks(path, inode)
+	if err != nil {
+		state.report("no se pudieron leer los bloques del inodo %d: %v", index, err)
+		return nil, nil, false
+	}
+	return blocks, pointers, true
+}
+
+func countUsed(bitmap []byte, used byte) int32 {
+	var count int32
+	for _, value := range bitmap {
+		if value == used {
+			count++
+		}
+	}
+	return count
+}
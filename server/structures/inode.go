@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -86,7 +87,7 @@ func (inode *Inode) Print() {
 	fmt.Printf("I_perm: %s\n", string(inode.I_perm[:]))
 }
 
-func (inode *Inode) HasPermissionsToWrite(userID, groupID int32) (bool, error) {
+func (inode *Inode) HasPermissionsToWrite(userID int32, groupIDs []int32) (bool, error) {
 	ownerUserId := inode.I_uid
 	ownerGroupId := inode.I_gid
 	permissions := string(inode.I_perm[:])
@@ -100,7 +101,7 @@ func (inode *Inode) HasPermissionsToWrite(userID, groupID int32) (bool, error) {
 			return true, nil
 		}
 	}
-	if ownerGroupId == groupID || userID == 1 {
+	if slices.Contains(groupIDs, ownerGroupId) || userID == 1 {
 		permGroup, err := strconv.Atoi(string(permissions[1]))
 		if err != nil {
 			return false, err
@@ -126,7 +127,7 @@ func (inode *Inode) HasPermissionsChmod(userID, groupID int32) (bool, error) {
 	}
 	return false, nil
 }
-func (inode *Inode) HasPermissionsToRead(userID, groupID int32) (bool, error) {
+func (inode *Inode) HasPermissionsToRead(userID int32, groupIDs []int32) (bool, error) {
 	ownerUserId := inode.I_uid
 	ownerGroupId := inode.I_gid
 	permissions := string(inode.I_perm[:])
@@ -140,7 +141,7 @@ func (inode *Inode) HasPermissionsToRead(userID, groupID int32) (bool, error) {
 			return true, nil
 		}
 	}
-	if ownerGroupId == groupID || userID == 1 {
+	if slices.Contains(groupIDs, ownerGroupId) || userID == 1 {
 		permGroup, err := strconv.Atoi(string(permissions[1]))
 		if err != nil {
 			return false, err
@@ -21,6 +21,18 @@ type Information struct {
 	I_date      float32
 }
 
+// Revisa que la ruta y el argumento quepan en la entrada del journal. Si se
+// cortaran, recovery repetiria la operacion sobre otra ruta.
+func CheckJournalFields(path, content string) error {
+	if len(path) > len(Information{}.I_path) {
+		return fmt.Errorf("la ruta %s es muy larga para guardarse en el journal (maximo %d caracteres)", path, len(Information{}.I_path))
+	}
+	if len(content) > len(Information{}.I_content) {
+		return fmt.Errorf("%s es muy largo para guardarse en el journal (maximo %d caracteres)", content, len(Information{}.I_content))
+	}
+	return nil
+}
+
 func (journal *Journal) Serialize(path string, offset int64) error {
 	// offset := journaling_start + (int64(binary.Size(Journal{})))*int64(journal.J_next)
 	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
@@ -68,6 +80,18 @@ func (journal *Journal) Deserialize(path string, offset int64) error {
 	return nil
 }
 
+func (journal *Journal) GetOperation() string {
+	return strings.Trim(string(journal.J_content.I_operation[:]), "\x00 ")
+}
+
+func (journal *Journal) GetPath() string {
+	return strings.Trim(string(journal.J_content.I_path[:]), "\x00 ")
+}
+
+func (journal *Journal) GetContent() string {
+	return strings.TrimRight(string(journal.J_content.I_content[:]), "\x00")
+}
+
 func (journal *Journal) Print() {
 	// Convertir el tiempo de montaje a una fecha
 	date := time.Unix(int64(journal.J_content.I_date), 0)
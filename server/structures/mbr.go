@@ -62,17 +62,24 @@ func (mbr *MBR) DeserializeMBR(path string) error {
 	return nil
 }
 
-func (mbr *MBR) GetFirstAvailablePartition() (*PARTITION, int, int) {
-	offset := binary.Size(mbr)
-
+// Devuelve el primer slot libre del MBR y el inicio del hueco donde cabe la
+// particion segun el ajuste del disco
+func (mbr *MBR) GetFirstAvailablePartition(sizeBytes int) (*PARTITION, int, int, error) {
+	index := -1
 	for i := 0; i < len(mbr.Mbr_partitions); i++ {
 		if mbr.Mbr_partitions[i].Part_start == -1 {
-			return &mbr.Mbr_partitions[i], offset, i
-		} else {
-			offset += int(mbr.Mbr_partitions[i].Part_size)
+			index = i
+			break
 		}
 	}
-	return nil, -1, -1
+	if index == -1 {
+		return nil, -1, -1, errors.New("no hay partitciones disponibles")
+	}
+	start, err := ChooseFreeSpace(mbr.GetFreeSpaces(), int32(sizeBytes), mbr.Mbr_disk_fit[0])
+	if err != nil {
+		return nil, -1, -1, errors.New("no se puede crear una particion por falta de espacio")
+	}
+	return &mbr.Mbr_partitions[index], int(start), index, nil
 }
 
 func (mbr *MBR) GetPartitionByName(name string) (*PARTITION, int) {
@@ -137,17 +144,6 @@ func (mbr *MBR) PrintPartitions() {
 	}
 }
 
-func (mbr *MBR) CanFitAnotherDisk(sizeBytes int) bool {
-	bytesUsed := binary.Size(mbr)
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		if mbr.Mbr_partitions[i].Part_size != -1 {
-			bytesUsed += int(mbr.Mbr_partitions[i].Part_size)
-		}
-	}
-	return bytesUsed+sizeBytes <= int(mbr.Mbr_size)
-
-}
-
 func (mbr *MBR) GetPartitionByID(id string) (*PARTITION, int, error) {
 	for i := 0; i < len(mbr.Mbr_partitions); i++ {
 		partitionID := strings.Trim(string(mbr.Mbr_partitions[i].Part_id[:]), "\x00 ")
@@ -43,6 +43,8 @@ func (p *PARTITION) MountPartition(correlative int, id string) error {
 
 	p.Part_correlative = int32(correlative)
 
+	// Un id mas corto que el anterior no debe dejar sus ultimos caracteres
+	p.Part_id = [4]byte{}
 	copy(p.Part_id[:], id)
 
 	return nil
@@ -3,6 +3,7 @@ package structures
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
 )
@@ -71,3 +72,184 @@ func (pb *PointerBlock) Print() {
 		fmt.Printf("VALOR %d: %d\n", i, value)
 	}
 }
+
+/*
+Distribucion de I_block:
+
+	0-11: apuntadores directos
+	12:   indirecto simple
+	13:   indirecto doble
+	14:   indirecto triple
+*/
+const (
+	DirectPointers = 12
+	SingleIndirect = 12
+	DoubleIndirect = 13
+	TripleIndirect = 14
+)
+
+func NewPointerBlock() *PointerBlock {
+	return &PointerBlock{
+		P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	}
+}
+
+// Nivel de indireccion del apuntador I_block[i], 0 si es directo
+func PointerLevel(i int) int {
+	if i < DirectPointers {
+		return 0
+	}
+	return i - DirectPointers + 1
+}
+
+// Cantidad de bloques de datos que alcanza un apuntador del nivel dado
+func pointerSpan(level int) int {
+	span := 1
+	for i := 0; i < level; i++ {
+		span *= len(PointerBlock{}.P_pointers)
+	}
+	return span
+}
+
+// Maximo de bloques de datos que puede tener un inodo
+func MaxInodeBlocks() int {
+	return DirectPointers + pointerSpan(1) + pointerSpan(2) + pointerSpan(3)
+}
+
+// Bloques que ocupa un archivo de dataBlocks bloques de datos, contando los de
+// apuntadores que hacen falta para enlazarlos
+func BlocksNeeded(dataBlocks int) int {
+	total := dataBlocks
+	remaining := dataBlocks - DirectPointers
+	for level := 1; level <= 3 && remaining > 0; level++ {
+		covered := min(remaining, pointerSpan(level))
+		for depth := 1; depth <= level; depth++ {
+			span := pointerSpan(depth)
+			total += (covered + span - 1) / span
+		}
+		remaining -= covered
+	}
+	return total
+}
+
+// Bloques de datos del inodo en orden logico (directos, simple, doble y triple)
+func (sb *SuperBlock) GetInodeBlocks(diskPath string, inode *Inode) ([]int32, error) {
+	blocks := make([]int32, 0)
+	for i, blockIndex := range inode.I_block {
+		if blockIndex == -1 {
+			continue
+		}
+		err := sb.walkPointer(diskPath, blockIndex, PointerLevel(i), &blocks, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// Bloques de apuntadores que usa el inodo, en cualquier nivel
+func (sb *SuperBlock) GetInodePointerBlocks(diskPath string, inode *Inode) ([]int32, error) {
+	pointers := make([]int32, 0)
+	for i, blockIndex := range inode.I_block {
+		if blockIndex == -1 || i < DirectPointers {
+			continue
+		}
+		err := sb.walkPointer(diskPath, blockIndex, PointerLevel(i), nil, &pointers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pointers, nil
+}
+
+func (sb *SuperBlock) walkPointer(diskPath string, blockIndex int32, level int, data *[]int32, pointers *[]int32) error {
+	if level == 0 {
+		if data != nil {
+			*data = append(*data, blockIndex)
+		}
+		return nil
+	}
+	if pointers != nil {
+		*pointers = append(*pointers, blockIndex)
+	}
+	pointerBlock := &PointerBlock{}
+	err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+	if err != nil {
+		return err
+	}
+	for _, value := range pointerBlock.P_pointers {
+		if value == -1 {
+			continue
+		}
+		err = sb.walkPointer(diskPath, value, level-1, data, pointers)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enlaza blockIndex como el bloque logico numero position del inodo, creando los
+// bloques de apuntadores intermedios que falten. No serializa el inodo.
+func (sb *SuperBlock) SetInodeBlock(diskPath string, inode *Inode, position int, blockIndex int32) error {
+	if position < DirectPointers {
+		inode.I_block[position] = blockIndex
+		return nil
+	}
+	position -= DirectPointers
+	for level := 1; level <= 3; level++ {
+		if position >= pointerSpan(level) {
+			position -= pointerSpan(level)
+			continue
+		}
+		slot := DirectPointers + level - 1
+		if inode.I_block[slot] == -1 {
+			pointerIndex, err := sb.newPointerBlock(diskPath)
+			if err != nil {
+				return err
+			}
+			inode.I_block[slot] = pointerIndex
+		}
+		pointerIndex := inode.I_block[slot]
+		for depth := level; depth > 0; depth-- {
+			pointerBlock := &PointerBlock{}
+			offset := int64(sb.S_block_start + (pointerIndex * sb.S_block_size))
+			err := pointerBlock.Deserialize(diskPath, offset)
+			if err != nil {
+				return err
+			}
+			span := pointerSpan(depth - 1)
+			index := position / span
+			position %= span
+			if depth == 1 {
+				pointerBlock.P_pointers[index] = blockIndex
+				return pointerBlock.Serialize(diskPath, offset)
+			}
+			if pointerBlock.P_pointers[index] == -1 {
+				newIndex, err := sb.newPointerBlock(diskPath)
+				if err != nil {
+					return err
+				}
+				pointerBlock.P_pointers[index] = newIndex
+				err = pointerBlock.Serialize(diskPath, offset)
+				if err != nil {
+					return err
+				}
+			}
+			pointerIndex = pointerBlock.P_pointers[index]
+		}
+	}
+	return errors.New("el inodo no tiene mas apuntadores disponibles")
+}
+
+func (sb *SuperBlock) newPointerBlock(diskPath string) (int32, error) {
+	pointerIndex, err := sb.AllocateBlock(diskPath)
+	if err != nil {
+		return -1, err
+	}
+	err = NewPointerBlock().Serialize(diskPath, int64(sb.S_block_start+(pointerIndex*sb.S_block_size)))
+	if err != nil {
+		return -1, err
+	}
+	return pointerIndex, nil
+}
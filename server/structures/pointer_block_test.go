@@ -0,0 +1,136 @@
+package structures
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Superbloque con solo el bitmap de bloques y los bloques, suficiente para
+// asignar bloques y enlazarlos en un inodo
+func newTestSuperBlock(t *testing.T, blocks int32) (*SuperBlock, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.dsk")
+	content := append(bytes.Repeat([]byte{'O'}, int(blocks)), make([]byte, blocks*64)...)
+	err := os.WriteFile(path, content, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SuperBlock{
+		S_free_blocks_count: blocks,
+		S_bm_block_start:    0,
+		S_block_start:       blocks,
+		S_block_size:        64,
+		S_fit:               [1]byte{'F'},
+	}, path
+}
+
+func newTestInode() *Inode {
+	inode := &Inode{I_type: [1]byte{'1'}}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	return inode
+}
+
+func TestBlocksNeeded(t *testing.T) {
+	tests := []struct {
+		dataBlocks int
+		want       int
+	}{
+		{0, 0},
+		{1, 1},
+		{12, 12},
+		{13, 14},                 // primer bloque del indirecto simple
+		{28, 29},                 // indirecto simple lleno
+		{29, 32},                 // doble: el bloque de primer nivel y uno de segundo
+		{44, 47},                 // primer bloque de segundo nivel lleno
+		{45, 49},                 // se agrega otro bloque de segundo nivel
+		{284, 302},               // indirecto doble lleno
+		{285, 306},               // triple: un bloque en cada uno de sus tres niveles
+		{MaxInodeBlocks(), 4671}, // 4380 de datos, 1 simple, 17 doble y 273 triple
+	}
+	for _, test := range tests {
+		got := BlocksNeeded(test.dataBlocks)
+		if got != test.want {
+			t.Errorf("BlocksNeeded(%d) = %d, se esperaba %d", test.dataBlocks, got, test.want)
+		}
+	}
+}
+
+func TestMaxInodeBlocks(t *testing.T) {
+	if got := MaxInodeBlocks(); got != 12+16+16*16+16*16*16 {
+		t.Errorf("MaxInodeBlocks() = %d", got)
+	}
+}
+
+func TestSetInodeBlock(t *testing.T) {
+	tests := []struct {
+		dataBlocks int
+		slots      []int // I_block que quedan en uso
+	}{
+		{12, []int{0, 11}},
+		{13, []int{11, 12}},
+		{28, []int{12}},
+		{29, []int{12, 13}},
+		{284, []int{13}},
+		{285, []int{13, 14}},
+		{MaxInodeBlocks(), []int{0, 11, 12, 13, 14}},
+	}
+	for _, test := range tests {
+		sb, path := newTestSuperBlock(t, int32(BlocksNeeded(MaxInodeBlocks())))
+		inode := newTestInode()
+		want := make([]int32, 0, test.dataBlocks)
+		for position := 0; position < test.dataBlocks; position++ {
+			blockIndex, err := sb.AllocateBlock(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = sb.SetInodeBlock(path, inode, position, blockIndex)
+			if err != nil {
+				t.Fatalf("SetInodeBlock(%d): %v", position, err)
+			}
+			want = append(want, blockIndex)
+		}
+
+		for _, slot := range test.slots {
+			if inode.I_block[slot] == -1 {
+				t.Errorf("%d bloques: I_block[%d] no se uso", test.dataBlocks, slot)
+			}
+		}
+		last := slices.Max(test.slots)
+		for slot := last + 1; slot < len(inode.I_block); slot++ {
+			if inode.I_block[slot] != -1 {
+				t.Errorf("%d bloques: I_block[%d] = %d, se esperaba -1", test.dataBlocks, slot, inode.I_block[slot])
+			}
+		}
+
+		blocks, err := sb.GetInodeBlocks(path, inode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(blocks, want) {
+			t.Errorf("%d bloques: GetInodeBlocks no devuelve los bloques en orden logico", test.dataBlocks)
+		}
+		pointers, err := sb.GetInodePointerBlocks(path, inode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks)+len(pointers) != BlocksNeeded(test.dataBlocks) {
+			t.Errorf("%d bloques: se usaron %d datos y %d apuntadores, BlocksNeeded dice %d", test.dataBlocks, len(blocks), len(pointers), BlocksNeeded(test.dataBlocks))
+		}
+		if int(sb.S_blocks_count) != BlocksNeeded(test.dataBlocks) {
+			t.Errorf("%d bloques: se asignaron %d bloques, BlocksNeeded dice %d", test.dataBlocks, sb.S_blocks_count, BlocksNeeded(test.dataBlocks))
+		}
+	}
+}
+
+func TestSetInodeBlockFull(t *testing.T) {
+	sb, path := newTestSuperBlock(t, 1)
+	err := sb.SetInodeBlock(path, newTestInode(), MaxInodeBlocks(), 0)
+	if err == nil {
+		t.Errorf("SetInodeBlock(%d) deberia fallar, el inodo no tiene mas apuntadores", MaxInodeBlocks())
+	}
+}
@@ -0,0 +1,111 @@
+package structures
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// Distribucion de un sistema de archivos con n inodos (los inicios son offsets
+// absolutos en el disco)
+type Layout struct {
+	N            int32
+	BmInodeStart int32
+	BmBlockStart int32
+	InodeStart   int32
+	BlockStart   int32
+}
+
+/*
+Mueve los bitmaps, la tabla de inodos y el area de bloques a la nueva
+distribucion y actualiza el superbloque (sin serializarlo). Los inodos y bloques
+conservan su indice, asi que los apuntadores siguen siendo validos. Si la nueva
+distribucion es mas chica, todo lo ocupado debe caber en ella. El journal no se
+mueve porque empieza justo despues del superbloque.
+*/
+func (sb *SuperBlock) Relocate(path string, journalStart int32, layout Layout) error {
+	oldInodes := sb.S_bm_block_start - sb.S_bm_inode_start
+	oldBlocks := sb.S_inode_start - sb.S_bm_block_start
+	neoInodes, neoBlocks := layout.N, 3*layout.N
+
+	bitmapInode, err := readBitmap(path, sb.S_bm_inode_start, oldInodes)
+	if err != nil {
+		return err
+	}
+	bitmapBlock, err := readBitmap(path, sb.S_bm_block_start, oldBlocks)
+	if err != nil {
+		return err
+	}
+	for i := neoInodes; i < oldInodes; i++ {
+		if bitmapInode[i] == '1' {
+			return fmt.Errorf("no se puede reducir el sistema de archivos, el inodo %d esta en uso", i)
+		}
+	}
+	for i := neoBlocks; i < oldBlocks; i++ {
+		if bitmapBlock[i] == 'X' {
+			return fmt.Errorf("no se puede reducir el sistema de archivos, el bloque %d esta en uso", i)
+		}
+	}
+	if sb.IsExt3() {
+		journals, err := sb.GetJournals(path, journalStart)
+		if err != nil {
+			return err
+		}
+		if int32(len(journals)) > neoInodes {
+			return fmt.Errorf("no se puede reducir el sistema de archivos, el journal tiene %d entradas", len(journals))
+		}
+	}
+
+	// Todo se lee antes de escribir porque la distribucion nueva se puede
+	// encimar con la anterior (readBitmap solo lee un rango de bytes)
+	keptInodes, keptBlocks := min(oldInodes, neoInodes), min(oldBlocks, neoBlocks)
+	inodeTable, err := readBitmap(path, sb.S_inode_start, keptInodes*sb.S_inode_size)
+	if err != nil {
+		return err
+	}
+	blockArea, err := readBitmap(path, sb.S_block_start, keptBlocks*sb.S_block_size)
+	if err != nil {
+		return err
+	}
+	neoBitmapInode := bytes.Repeat([]byte{'0'}, int(neoInodes))
+	copy(neoBitmapInode, bitmapInode[:keptInodes])
+	neoBitmapBlock := bytes.Repeat([]byte{'O'}, int(neoBlocks))
+	copy(neoBitmapBlock, bitmapBlock[:keptBlocks])
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	regions := []struct {
+		offset  int32
+		content []byte
+	}{
+		{layout.BmInodeStart, neoBitmapInode},
+		{layout.BmBlockStart, neoBitmapBlock},
+		{layout.InodeStart, inodeTable},
+		{layout.BlockStart, blockArea},
+		// Los inodos y bloques nuevos quedan en cero hasta que se asignen
+		{layout.InodeStart + keptInodes*sb.S_inode_size, make([]byte, (neoInodes-keptInodes)*sb.S_inode_size)},
+		{layout.BlockStart + keptBlocks*sb.S_block_size, make([]byte, (neoBlocks-keptBlocks)*sb.S_block_size)},
+	}
+	for _, region := range regions {
+		_, err = file.WriteAt(region.content, int64(region.offset))
+		if err != nil {
+			return err
+		}
+	}
+
+	usedInodes, usedBlocks := countUsed(neoBitmapInode, '1'), countUsed(neoBitmapBlock, 'X')
+	sb.S_inodes_count = usedInodes
+	sb.S_free_inodes_count = neoInodes - usedInodes
+	sb.S_blocks_count = usedBlocks
+	sb.S_free_blocks_count = neoBlocks - usedBlocks
+	sb.S_bm_inode_start = layout.BmInodeStart
+	sb.S_bm_block_start = layout.BmBlockStart
+	sb.S_inode_start = layout.InodeStart
+	sb.S_block_start = layout.BlockStart
+	sb.S_first_ino = sb.S_inode_start + firstFree(neoBitmapInode, '0')*sb.S_inode_size
+	sb.S_first_blo = sb.S_block_start + firstFree(neoBitmapBlock, 'O')*sb.S_block_size
+	return nil
+}
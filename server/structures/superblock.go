@@ -30,8 +30,15 @@ type SuperBlock struct {
 	S_bm_block_start    int32
 	S_inode_start       int32
 	S_block_start       int32
+	S_fit               [1]byte //Ajuste de la particion (B, F o W) para asignar inodos y bloques
+	S_version           int32   //Version de la distribucion en disco, ver LayoutVersion
 }
 
+// Version actual de la distribucion en disco. Las particiones formateadas antes
+// no guardaban el ajuste en el superbloque y usaban I_block[12] y [13] como
+// directos y [14] como indirecto simple, asi que no se pueden leer con esta.
+const LayoutVersion int32 = 2
+
 func (sb *SuperBlock) Serialize(path string, offset int64) error {
 	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
@@ -79,6 +86,14 @@ func (sb *SuperBlock) Deserialize(path string, offset int64) error {
 }
 
 func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) error {
+	rootInodeIndex, err := sb.AllocateInode(path)
+	if err != nil {
+		return err
+	}
+	rootBlockIndex, err := sb.AllocateBlock(path)
+	if err != nil {
+		return err
+	}
 	rootInode := &Inode{
 		I_uid:   1,
 		I_gid:   1,
@@ -86,25 +101,16 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		I_atime: float32(time.Now().Unix()),
 		I_ctime: float32(time.Now().Unix()),
 		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_block: [15]int32{rootBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
-	err := rootInode.Serialize(path, int64(sb.S_first_ino))
+	err = rootInode.Serialize(path, int64(sb.S_inode_start+(rootInodeIndex*sb.S_inode_size)))
 	if err != nil {
 		return err
 	}
 
-	err = sb.UpdateBitmapInode(path)
-	if err != nil {
-		return err
-	}
-
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-
 	rootBlock := &FolderBlock{
 		B_content: [4]FolderContent{
 			{B_name: [12]byte{'.'}, B_inodo: 0},
@@ -114,20 +120,11 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		},
 	}
 
-	err = sb.UpdateBitmapBlock(path)
+	err = rootBlock.Serialize(path, int64(sb.S_block_start+(rootBlockIndex*sb.S_block_size)))
 	if err != nil {
 		return err
 	}
 
-	err = rootBlock.Serialize(path, int64(sb.S_first_blo))
-	if err != nil {
-		return err
-	}
-
-	sb.S_blocks_count++
-	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
-
 	// Creamos el journal
 
 	if journauling_start != 0 {
@@ -146,7 +143,16 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		}
 	}
 
-	usersText := "1,G,root\n1,U,root,root,123\n"
+	rootPassword, err := utils.HashPassword(utils.DefaultRootPassword)
+	if err != nil {
+		return err
+	}
+	usersText := fmt.Sprintf("1,G,root\n1,U,root,root,%s\n", rootPassword)
+
+	usersInodeIndex, err := sb.AllocateInode(path)
+	if err != nil {
+		return err
+	}
 
 	err = rootInode.Deserialize(path, int64(sb.S_inode_start+0))
 	if err != nil {
@@ -165,7 +171,7 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		return err
 	}
 
-	rootBlock.B_content[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}
+	rootBlock.B_content[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: usersInodeIndex}
 
 	err = rootBlock.Serialize(path, int64(sb.S_block_start+0))
 	if err != nil {
@@ -178,24 +184,20 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		I_atime: float32(time.Now().Unix()),
 		I_ctime: float32(time.Now().Unix()),
 		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'1'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
-	err = sb.UpdateBitmapInode(path)
+	// Con el hash de la contraseña users.txt ya no cabe en un solo bloque
+	err = sb.WriteFileBlocks(path, usersInode, usersText)
 	if err != nil {
 		return err
 	}
-
-	err = usersInode.Serialize(path, int64(sb.S_first_ino))
+	err = usersInode.Serialize(path, int64(sb.S_inode_start+(usersInodeIndex*sb.S_inode_size)))
 	if err != nil {
 		return err
 	}
 
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-
 	// Crear Journal
 	if journauling_start != 0 {
 		journalFile := &Journal{
@@ -207,9 +209,7 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 				I_date:      float32(time.Now().Unix()),
 			},
 		}
-		// Copiamos el texto de usuarios en el journal
-		copy(journalFile.J_content.I_content[:], usersText)
-
+		// El contenido no se copia al journal porque lleva el hash de root
 		// err = journalFile.Serialize(path, journauling_start)
 		err = sb.AddJournal(journalFile, path, int32(journauling_start))
 		if err != nil {
@@ -217,26 +217,6 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 		}
 	}
 
-	usersBlock := &FileBlock{
-		B_content: [64]byte{},
-	}
-
-	copy(usersBlock.B_content[:], usersText)
-
-	err = usersBlock.Serialize(path, int64(sb.S_first_blo))
-	if err != nil {
-		return err
-	}
-
-	err = sb.UpdateBitmapBlock(path)
-	if err != nil {
-		return err
-	}
-
-	sb.S_blocks_count++
-	sb.S_free_blocks_count--
-	sb.S_first_blo += sb.S_block_size
-
 	// fmt.Println("\nInodo Raíz Actualizado:")
 	// rootInode.Print()
 
@@ -273,11 +253,17 @@ func (sb *SuperBlock) Print() {
 	fmt.Printf("Bitmap Block Start: %d\n", sb.S_bm_block_start)
 	fmt.Printf("Inode Start: %d\n", sb.S_inode_start)
 	fmt.Printf("Block Start: %d\n", sb.S_block_start)
+	fmt.Printf("Fit: %s\n", string(sb.S_fit[:]))
+	fmt.Printf("Version: %d\n", sb.S_version)
 }
 
 func (sb *SuperBlock) PrintInodes(path string) error {
 	fmt.Println("\nInodos\n----------------")
-	for i := int32(0); i < sb.S_inodes_count; i++ {
+	usedInodes, err := sb.GetUsedInodes(path)
+	if err != nil {
+		return err
+	}
+	for _, i := range usedInodes {
 		inode := &Inode{}
 		err := inode.Deserialize(path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
 		if err != nil {
@@ -292,16 +278,21 @@ func (sb *SuperBlock) PrintInodes(path string) error {
 
 func (sb *SuperBlock) PrintBlocks(path string) error {
 	fmt.Println("\nBloques\n----------------")
-	for i := int32(0); i < sb.S_inodes_count; i++ {
+	usedInodes, err := sb.GetUsedInodes(path)
+	if err != nil {
+		return err
+	}
+	for _, i := range usedInodes {
 		inode := &Inode{}
 		err := inode.Deserialize(path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
 		if err != nil {
 			return err
 		}
-		for _, blockIndex := range inode.I_block {
-			if blockIndex == -1 {
-				continue
-			}
+		blocks, err := sb.GetInodeBlocks(path, inode)
+		if err != nil {
+			return err
+		}
+		for _, blockIndex := range blocks {
 			if inode.I_type[0] == '0' {
 				block := &FolderBlock{}
 				err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
@@ -337,25 +328,90 @@ func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir str
 	}
 }
 
-func (sb *SuperBlock) AddJournal(neoJournal *Journal, path string, offset int32) error {
-	journal := &Journal{}
-	err := journal.Deserialize(path, int64(offset))
+// Agrega la entrada al final de la lista. start es el inicio del journal, que
+// tiene espacio para n entradas (una por inodo); si ya no cabe se devuelve error
+// en lugar de escribir sobre el bitmap de inodos.
+func (sb *SuperBlock) AddJournal(neoJournal *Journal, path string, start int32) error {
+	offset, journal, err := sb.journalTail(path, start)
 	if err != nil {
 		return err
 	}
-	if journal.J_next != -1 {
-		return sb.AddJournal(neoJournal, path, journal.J_next)
+	journalSize := int32(binary.Size(Journal{}))
+	position := offset + journalSize
+	if position+journalSize > start+sb.TotalInodes()*journalSize {
+		return errors.New("el journal esta lleno, no se puede registrar la operacion")
 	}
-	position := offset + int32(binary.Size(Journal{}))
 	journal.J_next = position
 	err = journal.Serialize(path, int64(offset))
 	if err != nil {
 		return err
 	}
-	neoJournal.Serialize(path, int64(position))
+	return neoJournal.Serialize(path, int64(position))
+}
+
+// Revisa que quepan entries entradas mas en el journal. Los comandos lo llaman
+// antes de modificar nada, si el journal se llenara a medias la operacion
+// quedaria hecha pero sin registrar.
+func (sb *SuperBlock) CheckJournalSpace(path string, start int32, entries int) error {
+	offset, _, err := sb.journalTail(path, start)
+	if err != nil {
+		return err
+	}
+	journalSize := int32(binary.Size(Journal{}))
+	if offset+int32(entries+1)*journalSize > start+sb.TotalInodes()*journalSize {
+		return errors.New("el journal esta lleno, no se puede registrar la operacion")
+	}
 	return nil
 }
 
+// Ultima entrada de la lista y su posicion
+func (sb *SuperBlock) journalTail(path string, start int32) (int32, *Journal, error) {
+	journalSize := int32(binary.Size(Journal{}))
+	end := start + sb.TotalInodes()*journalSize
+	offset := start
+	journal := &Journal{}
+	for {
+		err := journal.Deserialize(path, int64(offset))
+		if err != nil {
+			return -1, nil, err
+		}
+		if journal.J_next == -1 {
+			return offset, journal, nil
+		}
+		if journal.J_next < start || journal.J_next >= end {
+			return -1, nil, errors.New("el journal esta corrupto")
+		}
+		offset = journal.J_next
+	}
+}
+
+// Recorre la lista del journal desde su inicio y devuelve las entradas en orden
+func (sb *SuperBlock) GetJournals(path string, offset int32) ([]Journal, error) {
+	journals := make([]Journal, 0)
+	for offset != -1 {
+		if int32(len(journals)) > sb.S_inodes_count+sb.S_free_inodes_count {
+			return nil, errors.New("el journal esta corrupto")
+		}
+		journal := Journal{}
+		err := journal.Deserialize(path, int64(offset))
+		if err != nil {
+			return nil, err
+		}
+		journals = append(journals, journal)
+		offset = journal.J_next
+	}
+	return journals, nil
+}
+
+// Rechaza las particiones formateadas con una distribucion anterior. Si la
+// particion no tiene sistema de archivos no hay nada que revisar.
+func (sb *SuperBlock) CheckVersion() error {
+	if sb.S_magic != 0xEF53 || sb.S_version == LayoutVersion {
+		return nil
+	}
+	return errors.New("la particion fue formateada con una version anterior del sistema de archivos, vuelva a ejecutar mkfs")
+}
+
 func (sb *SuperBlock) IsExt3() bool {
 	return sb.S_filesystem_type == 3
 }
@@ -377,53 +433,14 @@ func (sb *SuperBlock) ChmodRecursive(diskPath string, indexInode int32, permissi
 			return err
 		}
 		if inode.I_type[0] == '0' {
-			for i, blockIndex := range inode.I_block {
-				if blockIndex == -1 {
-					continue
-				}
-				if i >= 14 {
-					pointerBlock := &PointerBlock{}
-					err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for _, value := range pointerBlock.P_pointers {
-						if value == -1 {
-							continue
-						}
-						block := &FolderBlock{}
-						err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-						if err != nil {
-							return err
-						}
-						for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-							content := block.B_content[indexContent]
-							if content.B_inodo == -1 {
-								continue
-							}
-							err := sb.ChmodRecursive(diskPath, content.B_inodo, permissions, userLogedId, userGroupID)
-							if err != nil {
-								return err
-							}
-						}
-					}
-				} else {
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.ChmodRecursive(diskPath, content.B_inodo, permissions, userLogedId, userGroupID)
-						if err != nil {
-							return err
-						}
-					}
-
+			children, err := sb.GetFolderChildren(diskPath, inode)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				err := sb.ChmodRecursive(diskPath, child, permissions, userLogedId, userGroupID)
+				if err != nil {
+					return err
 				}
 			}
 		}
@@ -455,53 +472,14 @@ func (sb *SuperBlock) ChownRecursive(diskPath string, indexInode int32, userLoge
 			return err
 		}
 		if inode.I_type[0] == '0' {
-			for i, blockIndex := range inode.I_block {
-				if blockIndex == -1 {
-					continue
-				}
-				if i >= 14 {
-					pointerBlock := &PointerBlock{}
-					err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for _, value := range pointerBlock.P_pointers {
-						if value == -1 {
-							continue
-						}
-						block := &FolderBlock{}
-						err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-						if err != nil {
-							return err
-						}
-						for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-							content := block.B_content[indexContent]
-							if content.B_inodo == -1 {
-								continue
-							}
-							err := sb.ChownRecursive(diskPath, content.B_inodo, userLogedId, userGroupID, userIdNeoOwner, false)
-							if err != nil {
-								return err
-							}
-						}
-					}
-				} else {
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.ChownRecursive(diskPath, content.B_inodo, userLogedId, userGroupID, userIdNeoOwner, false)
-						if err != nil {
-							return err
-						}
-					}
-
+			children, err := sb.GetFolderChildren(diskPath, inode)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				err := sb.ChownRecursive(diskPath, child, userLogedId, userGroupID, userIdNeoOwner, false)
+				if err != nil {
+					return err
 				}
 			}
 		}
@@ -509,13 +487,13 @@ func (sb *SuperBlock) ChownRecursive(diskPath string, indexInode int32, userLoge
 	return nil
 }
 
-func (sb *SuperBlock) MoveTreePermissions(diskPath string, indexInode int32, userLogedId, userGroupID int32) error {
+func (sb *SuperBlock) MoveTreePermissions(diskPath string, indexInode int32, userLogedId int32, userGroupIDs []int32) error {
 	inode := &Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+indexInode*sb.S_inode_size))
 	if err != nil {
 		return err
 	}
-	outcome, err := inode.HasPermissionsToWrite(userLogedId, userGroupID)
+	outcome, err := inode.HasPermissionsToWrite(userLogedId, userGroupIDs)
 	if err != nil {
 		return err
 	}
@@ -523,53 +501,14 @@ func (sb *SuperBlock) MoveTreePermissions(diskPath string, indexInode int32, use
 		return errors.New("hay un inodo que no tiene los permisos adecuados para tal accion")
 	}
 	if inode.I_type[0] == '0' {
-		for i, blockIndex := range inode.I_block {
-			if blockIndex == -1 {
-				continue
-			}
-			if i >= 14 {
-				pointerBlock := &PointerBlock{}
-				err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-				if err != nil {
-					return err
-				}
-				for _, value := range pointerBlock.P_pointers {
-					if value == -1 {
-						continue
-					}
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-					if err != nil {
-						return err
-					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.MoveTreePermissions(diskPath, content.B_inodo, userLogedId, userGroupID)
-						if err != nil {
-							return err
-						}
-					}
-				}
-			} else {
-				block := &FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-				if err != nil {
-					return err
-				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if content.B_inodo == -1 {
-						continue
-					}
-					err := sb.MoveTreePermissions(diskPath, content.B_inodo, userLogedId, userGroupID)
-					if err != nil {
-						return err
-					}
-				}
-
+		children, err := sb.GetFolderChildren(diskPath, inode)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			err := sb.MoveTreePermissions(diskPath, child, userLogedId, userGroupIDs)
+			if err != nil {
+				return err
 			}
 		}
 	}
@@ -596,7 +535,7 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 	if err != nil {
 		return -1, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return -1, err
 	}
@@ -604,7 +543,10 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 		return -1, nil
 	}
 	// Creamos el inodo
-	resultIndex := sb.S_inodes_count
+	resultIndex, err := sb.AllocateInode(diskPath)
+	if err != nil {
+		return -1, err
+	}
 	inodoCopia := &Inode{
 		I_uid:   inode.I_uid,
 		I_gid:   inode.I_gid,
@@ -616,50 +558,41 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 		I_type:  inode.I_type,
 		I_perm:  inode.I_perm,
 	}
-	offsetInodoCopia := int64(sb.S_first_ino)
+	offsetInodoCopia := int64(sb.S_inode_start + (resultIndex * sb.S_inode_size))
 	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
 		return -1, err
 	}
-	err = sb.UpdateBitmapInode(diskPath)
+	// Primer bloque con . y .., los demas se agregan conforme se copian las entradas
+	newBlockIndex, err := sb.AllocateBlock(diskPath)
+	if err != nil {
+		return -1, err
+	}
+	inodoCopia.I_block[0] = newBlockIndex
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: resultIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: indexInodoPadre},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	err = folderBlock.Serialize(diskPath, int64(sb.S_block_start+(newBlockIndex*sb.S_block_size)))
+	if err != nil {
+		return -1, err
+	}
+	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
 		return -1, err
 	}
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-	//
-
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		// Creamos un folderblock
-		inodoCopia.I_block[i] = sb.S_blocks_count
-		folderBlock := &FolderBlock{
-			B_content: [4]FolderContent{
-				{B_name: [12]byte{'.'}, B_inodo: resultIndex},
-				{B_name: [12]byte{'.', '.'}, B_inodo: indexInodoPadre},
-				{B_name: [12]byte{'-'}, B_inodo: -1},
-				{B_name: [12]byte{'-'}, B_inodo: -1},
-			},
-		}
-		offsetFolderBlock := int64(sb.S_first_blo)
-		err = folderBlock.Serialize(diskPath, offsetFolderBlock)
-		if err != nil {
-			return -1, err
-		}
-		err = sb.UpdateBitmapBlock(diskPath)
-		if err != nil {
-			return -1, err
-		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		//
 
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return -1, err
+	}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		err = block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 		if err != nil {
 			return -1, err
 		}
@@ -675,33 +608,22 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 			var inodoAIndexar int32
 			if tipoInodo == 0 {
 				inodoAIndexar, err = sb.CopyInode0(diskPath, content.B_inodo, resultIndex)
-				if err != nil {
-					return -1, err
-				}
-				if inodoAIndexar == -1 {
-					continue
-				}
 			} else {
 				inodoAIndexar, err = sb.CopyInode1(diskPath, content.B_inodo)
-				if err != nil {
-					return -1, err
-				}
-				if inodoAIndexar == -1 {
-					continue
-				}
 			}
-			copy(folderBlock.B_content[indexContent].B_name[:], content.B_name[:])
-			folderBlock.B_content[indexContent].B_inodo = inodoAIndexar
-		}
-		err = folderBlock.Serialize(diskPath, offsetFolderBlock)
-		if err != nil {
-			return -1, err
+			if err != nil {
+				return -1, err
+			}
+			if inodoAIndexar == -1 {
+				continue
+			}
+			name := strings.Trim(string(content.B_name[:]), "\x00")
+			err = sb.AddEntryToFolder(diskPath, resultIndex, name, inodoAIndexar)
+			if err != nil {
+				return -1, err
+			}
 		}
 	}
-	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
-	if err != nil {
-		return -1, err
-	}
 
 	return resultIndex, nil
 }
@@ -712,7 +634,7 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 	if err != nil {
 		return -1, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return -1, err
 	}
@@ -720,7 +642,10 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 		return -1, nil
 	}
 	// Creamos el inodo
-	resultIndex := sb.S_inodes_count
+	resultIndex, err := sb.AllocateInode(diskPath)
+	if err != nil {
+		return -1, err
+	}
 	inodoCopia := &Inode{
 		I_uid:   inode.I_uid,
 		I_gid:   inode.I_gid,
@@ -732,51 +657,18 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 		I_type:  inode.I_type,
 		I_perm:  inode.I_perm,
 	}
-	offsetInodoCopia := int64(sb.S_first_ino)
+	offsetInodoCopia := int64(sb.S_inode_start + (resultIndex * sb.S_inode_size))
 	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
 		return -1, err
 	}
-	err = sb.UpdateBitmapInode(diskPath)
+	content, err := sb.ReadFileContent(diskPath, indexInodeToCopy)
 	if err != nil {
 		return -1, err
 	}
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-	//
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		inodoCopia.I_block[i] = sb.S_blocks_count
-		contentBlock := &FileBlock{
-			B_content: [64]byte{},
-		}
-		offsetFolderBlock := int64(sb.S_first_blo)
-		err = contentBlock.Serialize(diskPath, offsetFolderBlock)
-		if err != nil {
-			return -1, err
-		}
-		err = sb.UpdateBitmapBlock(diskPath)
-		if err != nil {
-			return -1, err
-		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		//
-
-		blockToGetInfo := &FileBlock{}
-		err := blockToGetInfo.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-		if err != nil {
-			return -1, err
-		}
-		copy(contentBlock.B_content[:], blockToGetInfo.B_content[:])
-		err = contentBlock.Serialize(diskPath, offsetFolderBlock)
-		if err != nil {
-			return -1, err
-		}
+	err = sb.WriteFileBlocks(diskPath, inodoCopia, content)
+	if err != nil {
+		return -1, err
 	}
 	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
@@ -792,7 +684,7 @@ func (sb *SuperBlock) RemoveInodo1(diskPath string, indexInode int32) (bool, err
 	if err != nil {
 		return false, err
 	}
-	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return false, err
 	}
@@ -807,17 +699,18 @@ func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32) (bool, err
 	if err != nil {
 		return false, err
 	}
-	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return false, err
 	}
 	if !outcome {
 		return false, nil
 	}
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return false, err
+	}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 		if err != nil {
@@ -846,6 +739,11 @@ func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32) (bool, err
 				}
 			}
 			if row[indexContent-2] {
+				// El hijo ya quedo vacio, se liberan su inodo y sus bloques
+				err = sb.ReleaseInode(diskPath, content.B_inodo)
+				if err != nil {
+					return false, err
+				}
 				for j := range content.B_name {
 					content.B_name[j] = 0
 				}
@@ -858,9 +756,7 @@ func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32) (bool, err
 		if err != nil {
 			return false, err
 		}
-		if row[0] && row[1] {
-			inode.I_block[i] = -1
-		} else if !row[0] || !row[1] {
+		if !row[0] || !row[1] {
 			resultRemoval = false
 		}
 	}
@@ -872,24 +768,26 @@ func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32) (bool, err
 	return resultRemoval, nil
 }
 
-func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int, regex string) (string, error) {
+// Devuelve el arbol de coincidencias y agrega a hits la ruta completa de cada una
+func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int, regex string, path string, hits *[]string) (string, error) {
 	buffer := ""
 	inode := &Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
 	if err != nil {
 		return "", err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return "", err
 	}
 	if !outcome {
 		return "", nil
 	}
-	for _, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
+	blocks, err := sb.GetInodeBlocks(diskPath, inode)
+	if err != nil {
+		return "", err
+	}
+	for _, blockIndex := range blocks {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 		if err != nil {
@@ -915,7 +813,13 @@ func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int,
 			if tipoInodo == 0 {
 				// o que coincide o que viene algo
 				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				resultado, err := sb.CommandFind(diskPath, content.B_inodo, level+1, regex)
+				childPath := strings.TrimSuffix(path, "/") + "/" + contentName
+				re := regexp.MustCompile(regex)
+				matched := re.MatchString(contentName)
+				if matched {
+					*hits = append(*hits, childPath)
+				}
+				resultado, err := sb.CommandFind(diskPath, content.B_inodo, level+1, regex, childPath, hits)
 				if err != nil {
 					return "", err
 				}
@@ -923,8 +827,7 @@ func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int,
 					buffer += strings.Repeat("   ", level) + contentName + "\n" + resultado
 					continue
 				}
-				re := regexp.MustCompile(regex)
-				if re.MatchString(contentName) {
+				if matched {
 					buffer += strings.Repeat("   ", level) + contentName + "\n"
 				}
 			} else {
@@ -932,6 +835,7 @@ func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int,
 				re := regexp.MustCompile(regex)
 				if re.MatchString(contentName) {
 					buffer += strings.Repeat("   ", level) + contentName + "\n"
+					*hits = append(*hits, strings.TrimSuffix(path, "/")+"/"+contentName)
 				}
 			}
 		}
@@ -945,7 +849,7 @@ func (sb *SuperBlock) HasPermissionToCommandFind(diskPath string, indexInode int
 	if err != nil {
 		return false, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroups())
 	if err != nil {
 		return false, err
 	}
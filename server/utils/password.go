@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Las contraseñas se guardan con bcrypt
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// Contraseña de un usuario que no puede iniciar sesion, recovery la usa porque
+// el journal no guarda contraseñas
+const LockedPassword = "!"
+
+// Contraseña de root al formatear
+const DefaultRootPassword = "123"
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Indica si la contraseña ya esta guardada con bcrypt
+func IsHashedPassword(stored string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(stored, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compara la contraseña con lo guardado en users.txt, que puede seguir en
+// texto plano hasta que el usuario inicie sesion
+func CheckPassword(stored, password string) bool {
+	if stored == LockedPassword {
+		return false
+	}
+	if IsHashedPassword(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
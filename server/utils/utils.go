@@ -29,35 +29,63 @@ var alphabet = []string{
 var LogedUserID int32 = 1
 var LogedUserGroupID int32 = 1
 
+// Grupos adicionales del usuario logeado, ademas de LogedUserGroupID
+var LogedUserExtraGroupIDs []int32
+
+func LogedUserGroups() []int32 {
+	return append([]int32{LogedUserGroupID}, LogedUserExtraGroupIDs...)
+}
+
 var PathToLetter = make(map[string]string)
 
 var nextLetterIndex = 0
 
-var PathToPartitionCount = make(map[string]int)
 var letterCounterDisks int32 = 0
 
 func GetLetterToDisk() string {
+	if int(letterCounterDisks) >= len(alphabet) {
+		return ""
+	}
 	letter := alphabet[letterCounterDisks]
 	letterCounterDisks++
 	return letter
 }
 
-func GetLetter(path string) (string, int, error) {
-	if _, exists := PathToLetter[path]; !exists {
-		if nextLetterIndex < len(alphabet) {
-			PathToLetter[path] = alphabet[nextLetterIndex]
-			PathToPartitionCount[path] = 0
-			nextLetterIndex++
-		} else {
-			fmt.Println("Error: no hay más letras disponibles para asignar")
-			return "", 0, errors.New("no hay más letras disponibles para asignar")
-		}
+// Letra del disco, la primera vez se le asigna la siguiente disponible
+func GetLetter(path string) (string, error) {
+	if letter, exists := PathToLetter[path]; exists {
+		return letter, nil
+	}
+	used := make(map[string]bool)
+	for _, letter := range PathToLetter {
+		used[letter] = true
+	}
+	for nextLetterIndex < len(alphabet) && used[alphabet[nextLetterIndex]] {
+		nextLetterIndex++
 	}
+	if nextLetterIndex >= len(alphabet) {
+		return "", errors.New("no hay más letras disponibles para asignar")
+	}
+	SetLetter(path, alphabet[nextLetterIndex])
+	return PathToLetter[path], nil
+}
 
-	PathToPartitionCount[path]++
-	nextIndex := PathToPartitionCount[path]
+// Registra la letra del disco y continua la asignacion despues de ella
+func SetLetter(path, letter string) {
+	PathToLetter[path] = letter
+	for i, candidate := range alphabet {
+		if candidate == letter && i >= nextLetterIndex {
+			nextLetterIndex = i + 1
+		}
+	}
+}
 
-	return PathToLetter[path], nextIndex, nil
+// Restaura las letras asignadas a cada disco (por ejemplo al leer el estado
+// guardado) y continua la asignacion despues de la ultima letra usada
+func RestoreLetters(letters map[string]string) {
+	for path, letter := range letters {
+		SetLetter(path, letter)
+	}
 }
 
 func CreateParentDirs(path string) error {